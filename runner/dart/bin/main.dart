@@ -31,15 +31,16 @@ void main(List<String> args) async {
   print('=== Dart 测试运行器 ($mode 模式) ===');
 
   // 加载测试用例
-  final suite = await loadTestCases(testCasesPath);
+  final (suite, unsupported) = await loadTestCases(testCasesPath);
   print('加载了 ${suite.tests.length} 个测试用例');
 
   List<TestResult> testResults;
   int passed, failed;
+  var skipped = 0;
 
   switch (mode) {
     case 'api':
-      (testResults, passed, failed) = await runAPITests(suite);
+      (testResults, passed, failed, skipped) = await runAPITests(suite, unsupported);
       break;
     case 'wire':
       print('警告: Wire 模式在 Dart 版本中尚未实现，将跳过所有测试');
@@ -67,29 +68,93 @@ void main(List<String> args) async {
       total: testResults.length,
       passed: passed,
       failed: failed,
+      skipped: skipped,
     ),
   );
 
   await saveResults(outputPath, resultsFile);
 
   print('=== 测试完成 ===');
-  print('通过: $passed, 失败: $failed, 总计: ${testResults.length}');
+  print('通过: $passed, 失败: $failed, 跳过: $skipped, 总计: ${testResults.length}');
   print('结果已保存到: $outputPath');
 }
 
-/// 加载测试用例
-Future<TestSuite> loadTestCases(String path) async {
+/// 加载测试用例，同时返回使用了无法表示的 Extended JSON 类型的测试名称及该类型
+Future<(TestSuite, Map<String, String>)> loadTestCases(String path) async {
   final file = File(path);
   if (!await file.exists()) {
     throw FileSystemException('测试用例文件不存在', path);
   }
   final content = await file.readAsString();
-  final json = jsonDecode(content) as Map<String, dynamic>;
-  return TestSuite.fromJson(json);
+  final json = _relaxExtendedJson(jsonDecode(content)) as Map<String, dynamic>;
+  final unsupported = <String, String>{};
+  for (final test in (json['tests'] as List<dynamic>? ?? [])) {
+    final wrapper = _findUnsupportedType(test);
+    if (wrapper != null) unsupported[test['name'] as String] = wrapper;
+  }
+  return (TestSuite.fromJson(json), unsupported);
+}
+
+/// 运行器只能表示普通 JSON 值，展开数值包装后仍保留的 Extended JSON 类型包装
+const _unsupportedWrappers = {
+  r'$oid',
+  r'$date',
+  r'$numberDecimal',
+  r'$numberDouble',
+  r'$binary',
+  r'$regularExpression',
+  r'$timestamp',
+  r'$minKey',
+  r'$maxKey',
+};
+
+/// 返回值中第一个无法表示的 Extended JSON 类型包装，没有时返回 null
+String? _findUnsupportedType(dynamic value) {
+  if (value is Map<String, dynamic>) {
+    for (final key in value.keys) {
+      if (_unsupportedWrappers.contains(key)) return key;
+    }
+    for (final v in value.values) {
+      final found = _findUnsupportedType(v);
+      if (found != null) return found;
+    }
+  }
+  if (value is List) {
+    for (final v in value) {
+      final found = _findUnsupportedType(v);
+      if (found != null) return found;
+    }
+  }
+  return null;
 }
 
-/// 运行 API 模式测试
-Future<(List<TestResult>, int, int)> runAPITests(TestSuite suite) async {
+/// 将 Extended JSON v2 中的数值包装（$numberInt、$numberLong、$numberDouble）展开为普通数值；
+/// 其余类型包装保持不变，使用它们的测试在运行时跳过
+dynamic _relaxExtendedJson(dynamic value) {
+  if (value is Map<String, dynamic>) {
+    if (value.length == 1 && value.values.first is String) {
+      final text = value.values.first as String;
+      switch (value.keys.first) {
+        case r'$numberInt':
+        case r'$numberLong':
+          final n = int.tryParse(text);
+          if (n != null) return n;
+        case r'$numberDouble':
+          // NaN 和 Infinity 保持包装，使用它们的测试会被跳过
+          final d = double.tryParse(text);
+          if (d != null && d.isFinite) return d;
+      }
+    }
+    return value.map((k, v) => MapEntry(k, _relaxExtendedJson(v)));
+  }
+  if (value is List) {
+    return value.map(_relaxExtendedJson).toList();
+  }
+  return value;
+}
+
+/// 运行 API 模式测试；unsupported 中的测试使用了无法表示的类型，记录为跳过
+Future<(List<TestResult>, int, int, int)> runAPITests(TestSuite suite, Map<String, String> unsupported) async {
   // 删除现有数据库文件，确保从干净状态开始
   final dbFile = File(monoDBPath);
   if (await dbFile.exists()) {
@@ -105,11 +170,27 @@ Future<(List<TestResult>, int, int)> runAPITests(TestSuite suite) async {
   final results = <TestResult>[];
   var passed = 0;
   var failed = 0;
+  var skipped = 0;
 
   for (var i = 0; i < suite.tests.length; i++) {
     final tc = suite.tests[i];
     print('[${i + 1}/${suite.tests.length}] 测试: ${tc.name}');
 
+    final wrapper = unsupported[tc.name];
+    if (wrapper != null) {
+      final reason = '不支持的 Extended JSON 类型 $wrapper';
+      results.add(TestResult(
+        testName: tc.name,
+        language: 'dart',
+        mode: 'api',
+        status: 'skipped',
+        skipReason: reason,
+      ));
+      skipped++;
+      print('  - 跳过: $reason');
+      continue;
+    }
+
     try {
       final result = await runner.runTest(tc);
       results.add(result);
@@ -137,7 +218,7 @@ Future<(List<TestResult>, int, int)> runAPITests(TestSuite suite) async {
 
   await runner.close();
 
-  return (results, passed, failed);
+  return (results, passed, failed, skipped);
 }
 
 /// 保存测试结果
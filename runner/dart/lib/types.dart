@@ -144,6 +144,8 @@ class TestResult {
   int modifiedCount; // 修改数量
   int deletedCount; // 删除数量
   dynamic upsertedId; // Upsert ID
+  String? status; // 状态，跳过的测试为 skipped
+  String? skipReason; // 跳过原因
 
   TestResult({
     required this.testName,
@@ -158,6 +160,8 @@ class TestResult {
     this.modifiedCount = 0,
     this.deletedCount = 0,
     this.upsertedId,
+    this.status,
+    this.skipReason,
   });
 
   Map<String, dynamic> toJson() {
@@ -175,6 +179,8 @@ class TestResult {
     if (modifiedCount > 0) json['modified_count'] = modifiedCount;
     if (deletedCount > 0) json['deleted_count'] = deletedCount;
     if (upsertedId != null) json['upserted_id'] = upsertedId;
+    if (status != null) json['status'] = status;
+    if (skipReason != null) json['skip_reason'] = skipReason;
     return json;
   }
 }
@@ -3,17 +3,28 @@
 package main
 
 import (
+	"crypto/rand"
 	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/monolite/monodb/engine"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // APIRunner 使用库 API 直接测试
 // EN: APIRunner tests using the library API directly.
 type APIRunner struct {
 	db *engine.Database // 数据库实例 // EN: Database instance
+
+	mu        sync.Mutex      // 保护超时状态 // EN: Guards the timeout state
+	hungTest  string          // 超时后仍在运行的测试 // EN: Test still running after its timeout
+	abandoned <-chan struct{} // 该测试的协程结束时关闭 // EN: Closed when that test's goroutine finishes
 }
 
 // NewAPIRunner 创建 API 运行器
@@ -26,76 +37,227 @@ func NewAPIRunner(dbPath string) (*APIRunner, error) {
 	return &APIRunner{db: db}, nil
 }
 
-// Close 关闭数据库
-// EN: Close closes the database connection.
+// Close 关闭数据库；超时的测试仍在使用数据库时不关闭，由进程退出时释放
+// EN: Close closes the database connection; it is left open while a timed-out test is still using it and released when the process exits.
 func (r *APIRunner) Close() error {
+	if name, busy := r.hung(); busy {
+		log.Printf("警告: 超时的测试 %s 仍在运行，数据库未关闭", name) // EN: Warning: the timed-out test is still running, database left open
+		return nil
+	}
 	return r.db.Close()
 }
 
-// RunTest 运行单个测试
-// EN: RunTest runs a single test case.
+// Abandoned 返回超时后仍在运行的测试协程结束时关闭的通道
+// EN: Abandoned returns the channel closed when the test goroutine still running after its timeout finishes.
+func (r *APIRunner) Abandoned() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !pending(r.abandoned) {
+		return nil
+	}
+	return r.abandoned
+}
+
+// hung 返回超时后仍在运行的测试；该测试结束前数据库状态不可信
+// EN: hung returns the test still running after its timeout; the database state cannot be trusted until it finishes.
+func (r *APIRunner) hung() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hungTest, pending(r.abandoned)
+}
+
+// RunTest 在看门狗下运行单个测试；超时的测试仍在修改共享数据库时，后续测试直接判定失败
+// EN: RunTest runs a single test case under a watchdog; while a timed-out test is still mutating the shared database, later tests fail straight away.
 func (r *APIRunner) RunTest(tc TestCase) TestResult {
 	start := time.Now()
+	limit := testTimeout(tc)
+
+	if name, busy := r.hung(); busy {
+		msg := fmt.Sprintf("数据库仍被超时的测试 %s 使用", name) // EN: Database still in use by the timed-out test
+		return TestResult{TestName: tc.Name, Language: "go", Mode: "api", Error: msg, ActionError: msg}
+	}
+
+	result, abandoned := runWithWatchdog(limit, func() TestResult {
+		return r.runTest(tc)
+	})
+	if abandoned != nil {
+		r.mu.Lock()
+		r.hungTest, r.abandoned = tc.Name, abandoned
+		r.mu.Unlock()
+		result = TestResult{TestName: tc.Name, Language: "go", Mode: "api"}
+		markTimeout(&result, limit)
+	}
+
+	result.Duration = time.Since(start).Milliseconds()
+	return result
+}
+
+// runTest 执行前置步骤、测试动作和清理步骤
+// EN: runTest executes the setup steps, the test action and the teardown steps.
+func (r *APIRunner) runTest(tc TestCase) TestResult {
 	result := TestResult{
 		TestName: tc.Name,
 		Language: "go",
 		Mode:     "api",
 	}
 
-	// 执行前置步骤 // EN: Execute setup steps
-	if err := r.executeSetup(tc); err != nil {
+	// 执行前置步骤，成功后执行测试动作并校验预期结果
+	// EN: Execute setup steps, then the test action, and check the expected result
+	if err := r.executeSteps(tc.Collection, tc.Setup); err != nil {
 		result.Error = fmt.Sprintf("Setup 失败: %v", err) // EN: Setup failed
-		result.Duration = time.Since(start).Milliseconds()
-		return result
-	}
-
-	// 执行测试动作 // EN: Execute test action
-	if err := r.executeAction(tc, &result); err != nil {
-		result.Error = err.Error()
-		result.Duration = time.Since(start).Milliseconds()
-		// 检查是否是预期的错误 // EN: Check if this is an expected error
-		if tc.Expected.Error != "" {
-			result.Success = true
+		result.ActionError = result.Error
+	} else {
+		err := r.executeAction(tc, &result)
+		evaluateResult(tc, &result, err)
+		if tc.Transaction != nil {
+			evaluateTransaction(tc, &result)
+		}
+		if tc.Steps != nil {
+			evaluateScenario(tc, &result)
+		}
+		if tc.Verify != nil {
+			r.executeVerify(tc, &result)
 		}
-		return result
 	}
 
-	result.Success = true
-	result.Duration = time.Since(start).Milliseconds()
+	// 无论结果如何都执行清理步骤 // EN: Always execute teardown steps regardless of the outcome
+	if err := r.executeSteps(tc.Collection, tc.Teardown); err != nil {
+		recordTeardownFailure(&result, err)
+	}
 	return result
 }
 
-// executeSetup 执行前置步骤
-// EN: executeSetup executes the setup steps.
-func (r *APIRunner) executeSetup(tc TestCase) error {
-	if len(tc.Setup) == 0 {
+// executeVerify 在动作之后查询集合并校验其状态
+// EN: executeVerify queries the collection after the action and verifies its state.
+func (r *APIRunner) executeVerify(tc TestCase, result *TestResult) {
+	name := tc.Collection
+	if tc.Verify.Collection != "" {
+		name = tc.Verify.Collection
+	}
+	col, err := r.db.Collection(name)
+	if err != nil {
+		evaluateVerify(tc, result, nil, err)
+		return
+	}
+	query := TestCase{Action: TestAction{Method: "find", Filter: tc.Verify.Filter, Options: tc.Verify.Options}}
+	var verifyResult TestResult
+	err = r.executeFind(col, query, &verifyResult)
+	evaluateVerify(tc, result, verifyResult.RawDocuments, err)
+}
+
+// executeSteps 执行前置或清理步骤
+// EN: executeSteps executes setup or teardown steps.
+func (r *APIRunner) executeSteps(collection string, steps []SetupStep) error {
+	for _, step := range steps {
+		name := collection
+		if step.Collection != "" {
+			name = step.Collection
+		}
+		if err := r.executeStep(name, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeStep 在指定集合上执行单个步骤
+// EN: executeStep executes a single step on the given collection.
+func (r *APIRunner) executeStep(name string, step SetupStep) error {
+	// 集合级操作通过命令执行 // EN: Collection-level operations go through commands
+	switch step.Operation {
+	case "drop":
+		if _, err := r.runCommand(bson.D{{Key: "drop", Value: name}}); err != nil && !isNamespaceNotFound(err) {
+			return fmt.Errorf("删除集合失败: %w", err) // EN: Drop collection failed
+		}
+		return nil
+	case "createCollection":
+		if _, err := r.runCommand(bson.D{{Key: "create", Value: name}}); err != nil {
+			return fmt.Errorf("创建集合失败: %w", err) // EN: Create collection failed
+		}
 		return nil
 	}
 
-	col, err := r.db.Collection(tc.Collection)
+	col, err := r.db.Collection(name)
 	if err != nil {
 		return err
 	}
 
-	for _, step := range tc.Setup {
-		switch step.Operation {
-		case "insert":
-			doc := toBsonD(step.Data)
-			if _, err := col.Insert(doc); err != nil {
-				return fmt.Errorf("插入失败: %w", err) // EN: Insert failed
-			}
-		case "createIndex":
-			opts := toBsonD(step.Data)
-			keys := getField(opts, "keys").(bson.D)
-			indexOpts := getFieldD(opts, "options")
-			if _, err := col.CreateIndex(keys, indexOpts); err != nil {
-				return fmt.Errorf("创建索引失败: %w", err) // EN: Create index failed
-			}
+	switch step.Operation {
+	case "insert":
+		doc := toBsonD(step.Data)
+		if _, err := col.Insert(doc); err != nil {
+			return fmt.Errorf("插入失败: %w", err) // EN: Insert failed
+		}
+	case "insertMany":
+		docs := toBsonDSlice(toSlice(step.Data))
+		if _, err := col.Insert(docs...); err != nil {
+			return fmt.Errorf("批量插入失败: %w", err) // EN: Insert many failed
+		}
+	case "deleteMany":
+		filter := toBsonD(step.Data)
+		if filter == nil {
+			filter = bson.D{}
 		}
+		if _, err := col.Delete(filter); err != nil {
+			return fmt.Errorf("删除失败: %w", err) // EN: Delete failed
+		}
+	case "createIndex":
+		opts := toBsonD(step.Data)
+		keys := toBsonD(getField(opts, "keys"))
+		indexOpts := getFieldD(opts, "options")
+		if _, err := col.CreateIndex(keys, indexOpts); err != nil {
+			return fmt.Errorf("创建索引失败: %w", err) // EN: Create index failed
+		}
+	case "dropIndex":
+		indexName, _ := getField(toBsonD(step.Data), "name").(string)
+		if err := col.DropIndex(indexName); err != nil {
+			return fmt.Errorf("删除索引失败: %w", err) // EN: Drop index failed
+		}
+	default:
+		return fmt.Errorf("未知步骤: %s", step.Operation) // EN: Unknown step
 	}
 	return nil
 }
 
+// runCommand 执行数据库命令并检查 ok 字段
+// EN: runCommand runs a database command and checks the ok field.
+func (r *APIRunner) runCommand(cmd bson.D) (bson.D, error) {
+	reply, err := r.db.RunCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if ok := getField(reply, "ok"); ok != nil && toFloat64(ok) != 1 {
+		msg, _ := getField(reply, "errmsg").(string)
+		codeName, _ := getField(reply, "codeName").(string)
+		return nil, &commandError{Code: int32(toInt64(getField(reply, "code"))), CodeName: codeName, Message: msg}
+	}
+	return reply, nil
+}
+
+// commandError 命令回复或写错误中的错误，保留错误码以便按错误码断言
+// EN: commandError is an error taken from a command reply or a write error; the code is kept so assertions can match on it.
+type commandError struct {
+	Code     int32  // 错误码 // EN: Error code
+	CodeName string // 错误码名称，写错误中没有 // EN: Error code name, absent from write errors
+	Message  string // 错误信息 // EN: Error message
+}
+
+// Error 返回错误信息及其错误码名称或错误码
+// EN: Error returns the message together with its code name or code.
+func (e *commandError) Error() string {
+	if e.CodeName != "" {
+		return fmt.Sprintf("%s (%s)", e.Message, e.CodeName)
+	}
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// isNamespaceNotFound 判断错误是否为集合不存在
+// EN: isNamespaceNotFound reports whether the error means the collection does not exist.
+func isNamespaceNotFound(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "NamespaceNotFound") || strings.Contains(msg, "ns not found")
+}
+
 // executeAction 执行测试动作
 // EN: executeAction executes the test action.
 func (r *APIRunner) executeAction(tc TestCase, result *TestResult) error {
@@ -135,11 +297,46 @@ func (r *APIRunner) executeAction(tc TestCase, result *TestResult) error {
 		return r.executeListIndexes(col, tc, result)
 	case "dropIndex":
 		return r.executeDropIndex(col, tc, result)
+	case "findOneAndUpdate", "findOneAndReplace", "findOneAndDelete":
+		return r.executeFindOneAnd(col, tc, result)
+	case "countDocuments":
+		return r.executeCountDocuments(col, tc, result)
+	case "estimatedDocumentCount":
+		return r.executeEstimatedDocumentCount(tc, result)
+	case "bulkWrite":
+		return r.executeBulkWrite(tc, result)
+	case "drop":
+		return r.executeStep(tc.Collection, SetupStep{Operation: "drop"})
+	case "listCollections":
+		return r.executeListCollections(tc, result)
+	case "transaction":
+		return r.executeTransaction(tc, result)
+	case "steps":
+		result.Operations = runScenario(tc, "api", r.executeAction)
+		return nil
 	default:
 		return fmt.Errorf("未知方法: %s", tc.Action.Method) // EN: Unknown method
 	}
 }
 
+// apiMethods APIRunner 可执行的动作方法，与 executeAction 保持一致
+// EN: apiMethods lists the action methods APIRunner can execute, kept in sync with executeAction.
+var apiMethods = methodSet(
+	"insertOne", "insertMany", "find", "findOne", "updateOne", "updateMany",
+	"deleteOne", "deleteMany", "replaceOne", "findAndModify", "distinct", "aggregate",
+	"createIndex", "listIndexes", "dropIndex",
+	"findOneAndUpdate", "findOneAndReplace", "findOneAndDelete",
+	"countDocuments", "estimatedDocumentCount", "bulkWrite", "drop", "listCollections",
+	"transaction", "steps",
+)
+
+// apiTxnMethods APIRunner 在事务中可执行的方法，与 actionCommand 保持一致
+// EN: apiTxnMethods lists the methods APIRunner can execute inside a transaction, kept in sync with actionCommand.
+var apiTxnMethods = methodSet(
+	"insertOne", "insertMany", "find", "findOne", "updateOne", "updateMany",
+	"replaceOne", "deleteOne", "deleteMany", "aggregate",
+)
+
 // executeInsertOne 执行插入单个文档
 // EN: executeInsertOne executes insert one document.
 func (r *APIRunner) executeInsertOne(col *engine.Collection, tc TestCase, result *TestResult) error {
@@ -149,6 +346,7 @@ func (r *APIRunner) executeInsertOne(col *engine.Collection, tc TestCase, result
 		return err
 	}
 	result.Count = int64(len(ids))
+	result.InsertedIDs = ids
 	return nil
 }
 
@@ -161,6 +359,7 @@ func (r *APIRunner) executeInsertMany(col *engine.Collection, tc TestCase, resul
 		return err
 	}
 	result.Count = int64(len(ids))
+	result.InsertedIDs = ids
 	return nil
 }
 
@@ -202,7 +401,7 @@ func (r *APIRunner) executeFind(col *engine.Collection, tc TestCase, result *Tes
 	}
 
 	result.Count = int64(len(docs))
-	result.Documents = toMaps(docs)
+	result.setDocuments(docs)
 	return nil
 }
 
@@ -216,7 +415,7 @@ func (r *APIRunner) executeFindOne(col *engine.Collection, tc TestCase, result *
 	}
 	if doc != nil {
 		result.Count = 1
-		result.Documents = []bson.M{toMap(doc)}
+		result.setDocuments([]bson.D{doc})
 	} else {
 		result.Count = 0
 	}
@@ -229,16 +428,18 @@ func (r *APIRunner) executeUpdateOne(col *engine.Collection, tc TestCase, result
 	filter := toBsonD(tc.Action.Filter)
 	update := toBsonD(tc.Action.Update)
 
-	upsert := false
-	if tc.Action.Options != nil {
-		opts := toBsonD(tc.Action.Options)
-		if v := getField(opts, "upsert"); v != nil {
-			upsert = v.(bool)
-		}
+	upsert, arrayFilters := updateOptions(tc.Action.Options)
+	if arrayFilters != nil {
+		return r.executeUpdateCommand(tc.Collection, filter, update, false, upsert, arrayFilters, result)
 	}
 
-	// 使用 Update 方法但限制为单个文档 // EN: Use Update method but limit to single document
-	updateResult, err := col.Update(filter, update, upsert)
+	// Collection.Update 会更新所有匹配的文档，先将过滤条件限定到第一个匹配的文档
+	// EN: Collection.Update updates every matching document, so first restrict the filter to the first match
+	target, err := firstMatchFilter(col, filter)
+	if err != nil {
+		return err
+	}
+	updateResult, err := col.Update(target, update, upsert)
 	if err != nil {
 		return err
 	}
@@ -248,21 +449,148 @@ func (r *APIRunner) executeUpdateOne(col *engine.Collection, tc TestCase, result
 	return nil
 }
 
+// firstMatchFilter 返回只匹配第一个匹配文档的过滤条件：在原条件上追加该文档的 _id，
+// 保留原条件以便位置操作符 $ 仍能定位数组元素；没有匹配文档时返回原条件，供 upsert 使用
+// EN: firstMatchFilter returns a filter matching only the first matching document: the document's _id is added to the original filter,
+// EN: which is kept so the positional $ operator can still locate array elements; without a match the original filter is returned for upserts.
+func firstMatchFilter(col *engine.Collection, filter bson.D) (bson.D, error) {
+	if matchesAtMostOne(filter) {
+		return filter, nil
+	}
+
+	docs, err := col.FindWithOptions(filter, &engine.QueryOptions{
+		Limit:      1,
+		Projection: bson.D{{Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return filter, nil
+	}
+	id, _ := lookupField(docs[0], "_id")
+	return restrictToID(filter, id), nil
+}
+
+// matchesAtMostOne 判断过滤条件是否按 _id 标量等值匹配，这样最多只有一个文档；
+// 正则、操作符文档和数组（数组会匹配其中任一元素）都可能匹配多个文档
+// EN: matchesAtMostOne reports whether the filter is a scalar equality match on _id, which matches at most one document;
+// EN: a regex, an operator document or an array (which matches any of its elements) can match several documents.
+func matchesAtMostOne(filter bson.D) bool {
+	idFilter, hasID := lookupField(filter, "_id")
+	if !hasID {
+		return false
+	}
+	switch idFilter.(type) {
+	case primitive.Regex, bson.D, bson.M, map[string]any, bson.A, []any:
+		return false
+	default:
+		return true
+	}
+}
+
+// restrictToID 在过滤条件上追加 _id 等值条件，原条件不会被修改
+// EN: restrictToID adds an _id equality condition to the filter; the original filter is left untouched.
+func restrictToID(filter bson.D, id any) bson.D {
+	if _, hasID := lookupField(filter, "_id"); hasID {
+		// 原条件已包含 _id 操作符，用 $and 组合以免出现重复的键 // EN: The filter already holds an _id operator, combine with $and to avoid a duplicate key
+		return bson.D{{Key: "$and", Value: bson.A{filter, bson.D{{Key: "_id", Value: id}}}}}
+	}
+	return append(append(bson.D{}, filter...), bson.E{Key: "_id", Value: id})
+}
+
 // executeUpdateMany 执行更新多个文档
 // EN: executeUpdateMany executes update many documents.
 func (r *APIRunner) executeUpdateMany(col *engine.Collection, tc TestCase, result *TestResult) error {
 	filter := toBsonD(tc.Action.Filter)
 	update := toBsonD(tc.Action.Update)
 
-	updateResult, err := col.Update(filter, update, false)
+	upsert, arrayFilters := updateOptions(tc.Action.Options)
+	if arrayFilters != nil {
+		return r.executeUpdateCommand(tc.Collection, filter, update, true, upsert, arrayFilters, result)
+	}
+
+	updateResult, err := col.Update(filter, update, upsert)
 	if err != nil {
 		return err
 	}
 	result.MatchedCount = updateResult.MatchedCount
 	result.ModifiedCount = updateResult.ModifiedCount
+	result.UpsertedID = updateResult.UpsertedID
 	return nil
 }
 
+// executeUpdateCommand 通过 update 命令执行更新；Collection.Update 不支持 arrayFilters
+// EN: executeUpdateCommand performs the update through the update command, since Collection.Update does not support arrayFilters.
+func (r *APIRunner) executeUpdateCommand(name string, filter, update bson.D, multi, upsert bool, arrayFilters bson.A, result *TestResult) error {
+	reply, err := r.runCommand(updateCommand(name, filter, update, multi, upsert, arrayFilters))
+	if err != nil {
+		return err
+	}
+	return applyUpdateReply(reply, result)
+}
+
+// updateCommand 构造包含单条更新语句的 update 命令
+// EN: updateCommand builds an update command holding a single update statement.
+func updateCommand(name string, filter, update bson.D, multi, upsert bool, arrayFilters bson.A) bson.D {
+	statement := bson.D{
+		{Key: "q", Value: filter},
+		{Key: "u", Value: update},
+		{Key: "multi", Value: multi},
+		{Key: "upsert", Value: upsert},
+	}
+	if arrayFilters != nil {
+		statement = append(statement, bson.E{Key: "arrayFilters", Value: arrayFilters})
+	}
+	return bson.D{
+		{Key: "update", Value: name},
+		{Key: "updates", Value: bson.A{statement}},
+	}
+}
+
+// applyUpdateReply 将 update 命令的回复写入测试结果
+// EN: applyUpdateReply records the reply of an update command in the test result.
+func applyUpdateReply(reply bson.D, result *TestResult) error {
+	if err := replyWriteError(reply); err != nil {
+		return err
+	}
+
+	// n 包含 upsert 插入的文档 // EN: n includes upserted documents
+	upserted := toSlice(getField(reply, "upserted"))
+	result.MatchedCount = toInt64(getField(reply, "n")) - int64(len(upserted))
+	result.ModifiedCount = toInt64(getField(reply, "nModified"))
+	if len(upserted) > 0 {
+		result.UpsertedID = getField(toBsonD(upserted[0]), "_id")
+	}
+	return nil
+}
+
+// replyWriteError 返回写命令回复中的第一个写错误
+// EN: replyWriteError returns the first write error in the reply of a write command.
+func replyWriteError(reply bson.D) error {
+	writeErrors := toSlice(getField(reply, "writeErrors"))
+	if len(writeErrors) == 0 {
+		return nil
+	}
+	writeErr := toBsonD(writeErrors[0])
+	msg, _ := getField(writeErr, "errmsg").(string)
+	return &commandError{Code: int32(toInt64(getField(writeErr, "code"))), Message: msg}
+}
+
+// updateOptions 解析更新选项中的 upsert 和 arrayFilters
+// EN: updateOptions parses upsert and arrayFilters from the update options.
+func updateOptions(options any) (upsert bool, arrayFilters bson.A) {
+	if options == nil {
+		return false, nil
+	}
+	opts := toBsonD(options)
+	upsert, _ = getField(opts, "upsert").(bool)
+	if v := toSlice(getField(opts, "arrayFilters")); v != nil {
+		arrayFilters = bson.A(v)
+	}
+	return upsert, arrayFilters
+}
+
 // executeDeleteOne 执行删除单个文档
 // EN: executeDeleteOne executes delete one document.
 func (r *APIRunner) executeDeleteOne(col *engine.Collection, tc TestCase, result *TestResult) error {
@@ -292,6 +620,16 @@ func (r *APIRunner) executeDeleteMany(col *engine.Collection, tc TestCase, resul
 func (r *APIRunner) executeReplaceOne(col *engine.Collection, tc TestCase, result *TestResult) error {
 	filter := toBsonD(tc.Action.Filter)
 	replacement := toBsonD(tc.Action.Doc)
+
+	// Collection.ReplaceOne 不支持 upsert，此时通过 update 命令以替换文档执行，并返回插入文档的 _id
+	// EN: Collection.ReplaceOne does not support upserts, so those go through the update command with the replacement document, which also reports the inserted _id
+	if upsert, _ := updateOptions(tc.Action.Options); upsert {
+		if filter == nil {
+			filter = bson.D{}
+		}
+		return r.executeUpdateCommand(tc.Collection, filter, replacement, false, true, nil, result)
+	}
+
 	count, err := col.ReplaceOne(filter, replacement)
 	if err != nil {
 		return err
@@ -333,11 +671,201 @@ func (r *APIRunner) executeFindAndModify(col *engine.Collection, tc TestCase, re
 	}
 	if doc != nil {
 		result.Count = 1
-		result.Documents = []bson.M{toMap(doc)}
+		result.setDocuments([]bson.D{doc})
+	}
+	return nil
+}
+
+// executeFindOneAnd 执行 findOneAndUpdate、findOneAndReplace 或 findOneAndDelete；
+// FindAndModifyOptions 不支持 sort 和 projection，指定这些选项时改用 findAndModify 命令
+// EN: executeFindOneAnd executes findOneAndUpdate, findOneAndReplace or findOneAndDelete;
+// EN: FindAndModifyOptions supports neither sort nor projection, so the findAndModify command is used when they are given.
+func (r *APIRunner) executeFindOneAnd(col *engine.Collection, tc TestCase, result *TestResult) error {
+	opts := toBsonD(tc.Action.Options)
+	famOpts := &engine.FindAndModifyOptions{Query: toBsonD(tc.Action.Filter)}
+	switch tc.Action.Method {
+	case "findOneAndUpdate":
+		famOpts.Update = toBsonD(tc.Action.Update)
+	case "findOneAndReplace":
+		famOpts.Update = toBsonD(tc.Action.Doc)
+	case "findOneAndDelete":
+		famOpts.Remove = true
+	}
+	famOpts.New = getField(opts, "returnDocument") == "after"
+	famOpts.Upsert, _ = getField(opts, "upsert").(bool)
+
+	sortSpec, projection := getField(opts, "sort"), getField(opts, "projection")
+	var doc bson.D
+	if sortSpec == nil && projection == nil {
+		var err error
+		if doc, err = col.FindAndModify(famOpts); err != nil {
+			return err
+		}
+	} else {
+		query := famOpts.Query
+		if query == nil {
+			query = bson.D{}
+		}
+		cmd := bson.D{{Key: "findAndModify", Value: tc.Collection}, {Key: "query", Value: query}}
+		if famOpts.Remove {
+			cmd = append(cmd, bson.E{Key: "remove", Value: true})
+		} else {
+			cmd = append(cmd,
+				bson.E{Key: "update", Value: famOpts.Update},
+				bson.E{Key: "new", Value: famOpts.New},
+				bson.E{Key: "upsert", Value: famOpts.Upsert},
+			)
+		}
+		if sortSpec != nil {
+			cmd = append(cmd, bson.E{Key: "sort", Value: sortSpec})
+		}
+		if projection != nil {
+			cmd = append(cmd, bson.E{Key: "fields", Value: projection})
+		}
+		reply, err := r.runCommand(cmd)
+		if err != nil {
+			return err
+		}
+		doc = getFieldD(reply, "value")
+	}
+
+	if doc != nil {
+		result.Count = 1
+		result.setDocuments([]bson.D{doc})
+	}
+	return nil
+}
+
+// executeCountDocuments 统计匹配过滤条件的文档数，支持 skip 和 limit
+// EN: executeCountDocuments counts the documents matching the filter, honouring skip and limit.
+func (r *APIRunner) executeCountDocuments(col *engine.Collection, tc TestCase, result *TestResult) error {
+	opts := toBsonD(tc.Action.Options)
+	queryOpts := &engine.QueryOptions{}
+	if v := getField(opts, "skip"); v != nil {
+		queryOpts.Skip = toInt64(v)
 	}
+	if v := getField(opts, "limit"); v != nil {
+		queryOpts.Limit = toInt64(v)
+	}
+	docs, err := col.FindWithOptions(toBsonD(tc.Action.Filter), queryOpts)
+	if err != nil {
+		return err
+	}
+	result.Count = int64(len(docs))
 	return nil
 }
 
+// executeEstimatedDocumentCount 通过 count 命令返回集合的文档总数
+// EN: executeEstimatedDocumentCount returns the number of documents in the collection through the count command.
+func (r *APIRunner) executeEstimatedDocumentCount(tc TestCase, result *TestResult) error {
+	reply, err := r.runCommand(bson.D{{Key: "count", Value: tc.Collection}})
+	if err != nil {
+		return err
+	}
+	result.Count = toInt64(getField(reply, "n"))
+	return nil
+}
+
+// executeBulkWrite 将批量写入中的每个操作转换为对应的测试动作依次执行并累加结果；
+// ordered 为真（默认）时在第一个错误处停止，否则执行全部操作后返回第一个错误
+// EN: executeBulkWrite converts every operation of the bulk write into the matching test action, runs them in order and accumulates the results;
+// EN: when ordered is true (the default) it stops at the first error, otherwise it runs every operation and returns the first error.
+func (r *APIRunner) executeBulkWrite(tc TestCase, result *TestResult) error {
+	opts := toBsonD(tc.Action.Options)
+	ordered := true
+	if v, ok := getField(opts, "ordered").(bool); ok {
+		ordered = v
+	}
+
+	var firstErr error
+	for i, raw := range toSlice(getField(opts, "operations")) {
+		action, err := bulkOperationAction(toBsonD(raw))
+		if err == nil {
+			var opResult TestResult
+			err = r.executeAction(TestCase{Collection: tc.Collection, Action: action}, &opResult)
+			result.Count += opResult.Count
+			result.MatchedCount += opResult.MatchedCount
+			result.ModifiedCount += opResult.ModifiedCount
+			result.DeletedCount += opResult.DeletedCount
+			result.InsertedIDs = append(result.InsertedIDs, opResult.InsertedIDs...)
+			if result.UpsertedID == nil {
+				result.UpsertedID = opResult.UpsertedID
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("批量写入第 %d 个操作失败: %w", i, err) // EN: Bulk write operation %d failed
+			if ordered {
+				return err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// bulkOperationAction 将 {insertOne: {document}}、{updateOne: {filter, update, upsert}} 等批量写入操作转换为测试动作
+// EN: bulkOperationAction converts a bulk write operation such as {insertOne: {document}} or {updateOne: {filter, update, upsert}} into a test action.
+func bulkOperationAction(op bson.D) (TestAction, error) {
+	if len(op) != 1 {
+		return TestAction{}, fmt.Errorf("批量写入操作必须只有一个键: %v", op) // EN: A bulk write operation must have exactly one key
+	}
+	spec := toBsonD(op[0].Value)
+	action := TestAction{Method: op[0].Key, Filter: getField(spec, "filter")}
+	switch op[0].Key {
+	case "insertOne":
+		action.Doc = getField(spec, "document")
+	case "updateOne", "updateMany":
+		action.Update = getField(spec, "update")
+		action.Options = bson.D{
+			{Key: "upsert", Value: getField(spec, "upsert") == true},
+			{Key: "arrayFilters", Value: getField(spec, "arrayFilters")},
+		}
+	case "replaceOne":
+		action.Doc = getField(spec, "replacement")
+		action.Options = bson.D{{Key: "upsert", Value: getField(spec, "upsert") == true}}
+	case "deleteOne", "deleteMany":
+	default:
+		return TestAction{}, fmt.Errorf("未知的批量写入操作: %s", op[0].Key) // EN: Unknown bulk write operation
+	}
+	return action, nil
+}
+
+// executeListCollections 列出集合名称，按名称排序后以 {name} 文档返回
+// EN: executeListCollections lists the collection names and returns them as {name} documents sorted by name.
+func (r *APIRunner) executeListCollections(tc TestCase, result *TestResult) error {
+	cmd := bson.D{{Key: "listCollections", Value: 1}, {Key: "nameOnly", Value: true}}
+	if filter := toBsonD(tc.Action.Filter); filter != nil {
+		cmd = append(cmd, bson.E{Key: "filter", Value: filter})
+	}
+	reply, err := r.runCommand(cmd)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, c := range toSlice(getField(getFieldD(reply, "cursor"), "firstBatch")) {
+		if name, ok := getField(toBsonD(c), "name").(string); ok {
+			names = append(names, name)
+		}
+	}
+	result.Count = int64(len(names))
+	result.setDocuments(collectionDocs(names))
+	return nil
+}
+
+// collectionDocs 将集合名称排序并转换为 {name} 文档
+// EN: collectionDocs sorts the collection names and converts them into {name} documents.
+func collectionDocs(names []string) []bson.D {
+	sort.Strings(names)
+	docs := make([]bson.D, len(names))
+	for i, name := range names {
+		docs[i] = bson.D{{Key: "name", Value: name}}
+	}
+	return docs
+}
+
 // executeDistinct 执行去重查询
 // EN: executeDistinct executes distinct query.
 func (r *APIRunner) executeDistinct(col *engine.Collection, tc TestCase, result *TestResult) error {
@@ -392,7 +920,7 @@ func (r *APIRunner) executeAggregate(col *engine.Collection, tc TestCase, result
 		return err
 	}
 	result.Count = int64(len(docs))
-	result.Documents = toMaps(docs)
+	result.setDocuments(docs)
 	return nil
 }
 
@@ -410,7 +938,8 @@ func (r *APIRunner) executeCreateIndex(col *engine.Collection, tc TestCase, resu
 		return err
 	}
 	result.Count = 1
-	result.Documents = []bson.M{{"indexName": name}}
+	result.IndexName = name
+	result.setDocuments([]bson.D{{{Key: "indexName", Value: name}}})
 	return nil
 }
 
@@ -430,19 +959,266 @@ func (r *APIRunner) executeDropIndex(col *engine.Collection, tc TestCase, result
 	return col.DropIndex(name)
 }
 
+// apiSession 逻辑会话。engine 包没有可直接调用的会话或事务类型，事务只能通过 engine.Database.RunCommand
+// 以 lsid、txnNumber 和 autocommit 字段表达；这是引擎的进程内命令入口，不经过 Wire Protocol
+// EN: apiSession is a logical session. The engine package exposes no session or transaction type that can be called directly,
+// EN: so transactions are expressed through engine.Database.RunCommand with the lsid, txnNumber and autocommit fields;
+// EN: this is the engine's in-process command entry point and does not go through the wire protocol.
+type apiSession struct {
+	runner    *APIRunner // 执行命令的运行器 // EN: Runner executing the commands
+	lsid      bson.D     // 会话 ID // EN: Session ID
+	txnNumber int64      // 当前事务序号，每次开启事务时递增 // EN: Number of the current transaction, incremented whenever one starts
+	active    bool       // 是否处于事务中 // EN: Whether a transaction is in progress
+	sent      bool       // 当前事务是否已发送过命令 // EN: Whether a command has been sent in the current transaction
+}
+
+// newAPISession 创建带有随机 UUID 的逻辑会话
+// EN: newAPISession creates a logical session with a random UUID.
+func (r *APIRunner) newAPISession() (*apiSession, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("生成会话 ID 失败: %w", err) // EN: Failed to generate session ID
+	}
+	// UUID 第 4 版 // EN: UUID version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return &apiSession{
+		runner: r,
+		lsid:   bson.D{{Key: "id", Value: primitive.Binary{Subtype: 0x04, Data: id[:]}}},
+	}, nil
+}
+
+// startTransaction 开启新事务；事务在第一条命令到达引擎时才真正开始
+// EN: startTransaction starts a new transaction; it only really begins when its first command reaches the engine.
+func (s *apiSession) startTransaction() error {
+	if s.active {
+		return fmt.Errorf("事务已在进行中") // EN: A transaction is already in progress
+	}
+	s.txnNumber++
+	s.active, s.sent = true, false
+	return nil
+}
+
+// commitTransaction 提交当前事务
+// EN: commitTransaction commits the current transaction.
+func (s *apiSession) commitTransaction() error {
+	return s.endTransaction("commitTransaction")
+}
+
+// abortTransaction 回滚当前事务
+// EN: abortTransaction aborts the current transaction.
+func (s *apiSession) abortTransaction() error {
+	return s.endTransaction("abortTransaction")
+}
+
+// endTransaction 结束当前事务；没有发送过命令的事务在引擎中不存在，直接结束
+// EN: endTransaction ends the current transaction; a transaction that never sent a command does not exist in the engine and simply ends.
+func (s *apiSession) endTransaction(name string) error {
+	if !s.active {
+		return fmt.Errorf("没有进行中的事务") // EN: No transaction in progress
+	}
+	s.active = false
+	if !s.sent {
+		return nil
+	}
+	_, err := s.runner.runCommand(bson.D{
+		{Key: name, Value: 1},
+		{Key: "lsid", Value: s.lsid},
+		{Key: "txnNumber", Value: s.txnNumber},
+		{Key: "autocommit", Value: false},
+	})
+	return err
+}
+
+// wrap 为命令附加会话和事务字段，事务的第一条命令同时开启事务
+// EN: wrap appends the session and transaction fields to a command; the first command of a transaction also starts it.
+func (s *apiSession) wrap(cmd bson.D) bson.D {
+	cmd = append(cmd,
+		bson.E{Key: "lsid", Value: s.lsid},
+		bson.E{Key: "txnNumber", Value: s.txnNumber},
+		bson.E{Key: "autocommit", Value: false},
+	)
+	if !s.sent {
+		cmd = append(cmd, bson.E{Key: "startTransaction", Value: true})
+		s.sent = true
+	}
+	return cmd
+}
+
+// executeTransaction 在会话中开启事务并依次执行操作，最后按测试定义提交或回滚；
+// 并发事务中的操作使用另一个会话，该事务总是在最后回滚
+// EN: executeTransaction starts a transaction in a session, runs the operations in order and commits or aborts as the test defines;
+// EN: operations of the concurrent transaction use a second session, whose transaction is always aborted at the end.
+func (r *APIRunner) executeTransaction(tc TestCase, result *TestResult) error {
+	if tc.Transaction == nil {
+		return fmt.Errorf("缺少事务定义") // EN: Missing transaction definition
+	}
+	txn, err := r.newAPISession()
+	if err != nil {
+		return err
+	}
+	other, err := r.newAPISession()
+	if err != nil {
+		return err
+	}
+	if err := txn.startTransaction(); err != nil {
+		return err
+	}
+	if err := other.startTransaction(); err != nil {
+		return err
+	}
+
+	for _, op := range tc.Transaction.Operations {
+		name := tc.Collection
+		if op.Collection != "" {
+			name = op.Collection
+		}
+		opCase := TestCase{Collection: name, Action: op.Action}
+		opResult := TestResult{TestName: op.Action.Method, Language: "go", Mode: "api"}
+
+		var opErr error
+		switch op.Session {
+		case SessionNone:
+			opErr = r.executeAction(opCase, &opResult)
+		case SessionOther:
+			opErr = r.executeInSession(other, opCase, &opResult)
+		default:
+			opErr = r.executeInSession(txn, opCase, &opResult)
+		}
+		if opErr != nil {
+			recordActionError(&opResult, opErr)
+		}
+		opResult.Success = opErr == nil
+		result.Operations = append(result.Operations, opResult)
+	}
+
+	// 冲突后并发事务可能已被引擎回滚，忽略回滚错误
+	// EN: The concurrent transaction may already have been aborted by the engine after a conflict, so abort errors are ignored
+	other.abortTransaction()
+	if tc.Transaction.Outcome == "abort" {
+		return txn.abortTransaction()
+	}
+	return txn.commitTransaction()
+}
+
+// executeInSession 将测试动作转换为命令并在会话的事务中执行
+// EN: executeInSession converts the test action into a command and runs it inside the transaction of the session.
+func (r *APIRunner) executeInSession(s *apiSession, tc TestCase, result *TestResult) error {
+	cmd, err := actionCommand(tc)
+	if err != nil {
+		return err
+	}
+	reply, err := r.runCommand(s.wrap(cmd))
+	if err != nil {
+		return err
+	}
+
+	switch tc.Action.Method {
+	case "insertOne", "insertMany":
+		if err := replyWriteError(reply); err != nil {
+			return err
+		}
+		result.Count = toInt64(getField(reply, "n"))
+	case "find", "findOne", "aggregate":
+		docs := toBsonDSlice(toSlice(getField(getFieldD(reply, "cursor"), "firstBatch")))
+		result.Count = int64(len(docs))
+		result.setDocuments(docs)
+	case "updateOne", "updateMany", "replaceOne":
+		return applyUpdateReply(reply, result)
+	case "deleteOne", "deleteMany":
+		if err := replyWriteError(reply); err != nil {
+			return err
+		}
+		result.DeletedCount = toInt64(getField(reply, "n"))
+	}
+	return nil
+}
+
+// actionCommand 将事务中支持的测试动作转换为对应的数据库命令
+// EN: actionCommand converts a test action supported inside transactions into the matching database command.
+func actionCommand(tc TestCase) (bson.D, error) {
+	name := tc.Collection
+	filter := toBsonD(tc.Action.Filter)
+	if filter == nil {
+		filter = bson.D{}
+	}
+	opts := toBsonD(tc.Action.Options)
+
+	switch tc.Action.Method {
+	case "insertOne":
+		return bson.D{{Key: "insert", Value: name}, {Key: "documents", Value: bson.A{toBsonD(tc.Action.Doc)}}}, nil
+	case "insertMany":
+		docs := bson.A{}
+		for _, d := range toBsonDSlice(tc.Action.Docs) {
+			docs = append(docs, d)
+		}
+		return bson.D{{Key: "insert", Value: name}, {Key: "documents", Value: docs}}, nil
+	case "find", "findOne":
+		cmd := bson.D{{Key: "find", Value: name}, {Key: "filter", Value: filter}}
+		for _, key := range []string{"sort", "skip", "limit", "projection"} {
+			if v := getField(opts, key); v != nil {
+				cmd = append(cmd, bson.E{Key: key, Value: v})
+			}
+		}
+		if tc.Action.Method == "findOne" {
+			cmd = append(cmd, bson.E{Key: "limit", Value: int64(1)}, bson.E{Key: "singleBatch", Value: true})
+		}
+		return cmd, nil
+	case "updateOne", "updateMany":
+		upsert, arrayFilters := updateOptions(tc.Action.Options)
+		multi := tc.Action.Method == "updateMany"
+		return updateCommand(name, filter, toBsonD(tc.Action.Update), multi, upsert, arrayFilters), nil
+	case "replaceOne":
+		upsert, _ := updateOptions(tc.Action.Options)
+		return updateCommand(name, filter, toBsonD(tc.Action.Doc), false, upsert, nil), nil
+	case "deleteOne", "deleteMany":
+		limit := int32(0)
+		if tc.Action.Method == "deleteOne" {
+			limit = 1
+		}
+		return bson.D{
+			{Key: "delete", Value: name},
+			{Key: "deletes", Value: bson.A{bson.D{{Key: "q", Value: filter}, {Key: "limit", Value: limit}}}},
+		}, nil
+	case "aggregate":
+		return bson.D{
+			{Key: "aggregate", Value: name},
+			{Key: "pipeline", Value: convertValue(getField(opts, "pipeline"))},
+			{Key: "cursor", Value: bson.D{}},
+		}, nil
+	default:
+		return nil, fmt.Errorf("事务中不支持的方法: %s", tc.Action.Method) // EN: Method not supported inside a transaction
+	}
+}
+
 // toBsonD 辅助函数: 将 any 类型转换为 bson.D
+// Extended JSON 解码得到的 bson.D 保持原有字段顺序（排序键、复合索引、管道阶段依赖此顺序）；
+// map 本身没有顺序，只能按键名排序以保证结果确定
 // EN: toBsonD is a helper function to convert any type to bson.D.
+// EN: A bson.D decoded from Extended JSON keeps its field order (sort keys, compound indexes and pipeline stages rely on it);
+// EN: maps have no order, so their keys are sorted to keep the result deterministic.
 func toBsonD(v any) bson.D {
 	if v == nil {
 		return nil
 	}
 	switch val := v.(type) {
 	case bson.D:
-		return val
+		result := make(bson.D, len(val))
+		for i, e := range val {
+			result[i] = bson.E{Key: e.Key, Value: convertValue(e.Value)}
+		}
+		return result
+	case bson.M:
+		return toBsonD(map[string]interface{}(val))
 	case map[string]interface{}:
-		result := bson.D{}
-		for k, v := range val {
-			result = append(result, bson.E{Key: k, Value: convertValue(v)})
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		result := make(bson.D, 0, len(val))
+		for _, k := range keys {
+			result = append(result, bson.E{Key: k, Value: convertValue(val[k])})
 		}
 		return result
 	default:
@@ -470,8 +1246,10 @@ func convertValue(v any) any {
 		return nil
 	}
 	switch val := v.(type) {
-	case map[string]interface{}:
+	case bson.D, bson.M, map[string]interface{}:
 		return toBsonD(val)
+	case bson.A:
+		return convertValue([]interface{}(val))
 	case []interface{}:
 		result := bson.A{}
 		for _, item := range val {
@@ -528,16 +1306,74 @@ func toInt64(v interface{}) int64 {
 	}
 }
 
+// toFloat64 将数值转换为 float64
+// EN: toFloat64 converts a numeric value to float64.
+func toFloat64(v interface{}) float64 {
+	if f, ok := v.(float64); ok {
+		return f
+	}
+	return float64(toInt64(v))
+}
+
+// toSlice 将数组值转换为 []any
+// EN: toSlice converts an array value to []any.
+func toSlice(v any) []any {
+	switch val := v.(type) {
+	case []any:
+		return val
+	case bson.A:
+		return val
+	default:
+		return nil
+	}
+}
+
 // toMap 将 bson.D 转换为 bson.M
 // EN: toMap converts bson.D to bson.M.
 func toMap(doc bson.D) bson.M {
 	m := bson.M{}
 	for _, e := range doc {
-		m[e.Key] = e.Value
+		m[e.Key] = toPlainValue(e.Value)
 	}
 	return m
 }
 
+// toPlainValue 递归将嵌套的 bson.D 转换为 bson.M，便于 JSON 输出
+// JSON 无法表示 NaN 和无穷大，这些值以 Extended JSON 的字符串形式输出
+// EN: toPlainValue recursively converts nested bson.D values to bson.M for JSON output.
+// EN: JSON cannot represent NaN and infinities, so they are emitted as their Extended JSON strings.
+func toPlainValue(v any) any {
+	switch val := v.(type) {
+	case bson.D:
+		return toMap(val)
+	case bson.A:
+		result := make(bson.A, len(val))
+		for i, item := range val {
+			result[i] = toPlainValue(item)
+		}
+		return result
+	case float64:
+		switch {
+		case math.IsNaN(val):
+			return "NaN"
+		case math.IsInf(val, 1):
+			return "Infinity"
+		case math.IsInf(val, -1):
+			return "-Infinity"
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// setDocuments 记录返回文档，同时保留有序版本用于比较
+// EN: setDocuments records returned documents and keeps the ordered form for comparison.
+func (r *TestResult) setDocuments(docs []bson.D) {
+	r.RawDocuments = docs
+	r.Documents = toMaps(docs)
+}
+
 // toMaps 将 []bson.D 转换为 []bson.M
 // EN: toMaps converts []bson.D to []bson.M.
 func toMaps(docs []bson.D) []bson.M {
@@ -0,0 +1,88 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMatchesAtMostOne(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter bson.D
+		want   bool
+	}{
+		{"empty filter", bson.D{}, false},
+		{"other field", bson.D{{Key: "status", Value: "a"}}, false},
+		{"_id equality", bson.D{{Key: "_id", Value: "x"}}, true},
+		{"_id equality with other fields", bson.D{{Key: "status", Value: "a"}, {Key: "_id", Value: int32(1)}}, true},
+		{"_id operator", bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{"x", "y"}}}}}, false},
+		{"_id operator map", bson.D{{Key: "_id", Value: bson.M{"$gt": int32(1)}}}, false},
+		{"_id regex", bson.D{{Key: "_id", Value: primitive.Regex{Pattern: "^a"}}}, false},
+		{"_id array", bson.D{{Key: "_id", Value: bson.A{"x", "y"}}}, false},
+		{"_id ObjectId", bson.D{{Key: "_id", Value: primitive.NewObjectID()}}, true},
+	}
+	for _, tt := range tests {
+		if got := matchesAtMostOne(tt.filter); got != tt.want {
+			t.Errorf("%s: matchesAtMostOne = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRestrictToID(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter bson.D
+		want   bson.D
+	}{
+		{
+			name:   "empty filter",
+			filter: bson.D{},
+			want:   bson.D{{Key: "_id", Value: "x"}},
+		},
+		{
+			name:   "appends _id and keeps the original conditions",
+			filter: bson.D{{Key: "tags", Value: "a"}, {Key: "n", Value: bson.D{{Key: "$gt", Value: int32(1)}}}},
+			want: bson.D{
+				{Key: "tags", Value: "a"},
+				{Key: "n", Value: bson.D{{Key: "$gt", Value: int32(1)}}},
+				{Key: "_id", Value: "x"},
+			},
+		},
+		{
+			name:   "combines with an _id operator through $and",
+			filter: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{"x", "y"}}}}},
+			want: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{"x", "y"}}}}},
+				bson.D{{Key: "_id", Value: "x"}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append(bson.D{}, tt.filter...)
+			got := restrictToID(tt.filter, "x")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("restrictToID = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.filter, original) {
+				t.Errorf("filter was modified: %v, want %v", tt.filter, original)
+			}
+		})
+	}
+}
+
+func TestRestrictToIDDoesNotShareBackingArray(t *testing.T) {
+	filter := make(bson.D, 1, 4)
+	filter[0] = bson.E{Key: "n", Value: int32(1)}
+	a := restrictToID(filter, "a")
+	b := restrictToID(filter, "b")
+	if a[1].Value != "a" || b[1].Value != "b" {
+		t.Errorf("restricted filters share storage: %v, %v", a, b)
+	}
+}
@@ -0,0 +1,320 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// evaluateResult 根据动作错误和预期结果判定测试是否通过
+// EN: evaluateResult decides whether a test passed from the action error and the expected result.
+func evaluateResult(tc TestCase, result *TestResult, actionErr error) {
+	if actionErr != nil {
+		recordActionError(result, actionErr)
+	}
+
+	failures := checkExpected(tc.Expected, tc.Comparison, result, actionErr)
+	if tc.Reference != nil {
+		failures = append(failures, checkReference(tc, result, actionErr)...)
+	}
+	if len(failures) == 0 {
+		result.Success = true
+		return
+	}
+	result.Success = false
+	result.AssertionFailures = failures
+	result.Error = summarizeFailures(failures)
+}
+
+// evaluateVerify 比较状态校验查询返回的文档，不一致时将测试标记为失败
+// EN: evaluateVerify compares the documents returned by the verification query and marks the test as failed on mismatch.
+func evaluateVerify(tc TestCase, result *TestResult, docs []bson.D, verifyErr error) {
+	var failures []AssertionFailure
+	if verifyErr != nil {
+		failures = []AssertionFailure{newFailure("verify.error", nil, verifyErr.Error())}
+	} else {
+		result.RawVerifyDocuments = docs
+		result.VerifyDocuments = toMaps(docs)
+
+		comparator := newComparator(tc.Comparison)
+		ordered := tc.Comparison != nil && tc.Comparison.Ordered
+		failures = comparator.compareResultSet("verify.documents", tc.Verify.Documents, docs, ordered)
+		// 参考结果中记录了校验文档时一并比较 // EN: Also compare with the verification documents recorded in the reference result
+		if tc.Reference != nil && tc.Reference.VerifyDocuments != nil {
+			failures = append(failures, checkReferenceVerify(tc, docs)...)
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+	result.Success = false
+	result.AssertionFailures = append(result.AssertionFailures, failures...)
+	result.Error = summarizeFailures(result.AssertionFailures)
+}
+
+// evaluateTransaction 检查事务中每个操作的预期结果，失败的字段路径带有操作序号
+// EN: evaluateTransaction checks the expected result of every transaction operation; failing field paths carry the operation index.
+func evaluateTransaction(tc TestCase, result *TestResult) {
+	var failures []AssertionFailure
+	for i, op := range tc.Transaction.Operations {
+		prefix := fmt.Sprintf("transaction.operations[%d].", i)
+		if i >= len(result.Operations) {
+			failures = append(failures, newFailure(prefix+"executed", true, false))
+			continue
+		}
+		opResult := &result.Operations[i]
+		var opErr error
+		if opResult.ActionError != "" {
+			opErr = errors.New(opResult.ActionError)
+		}
+		for _, f := range checkExpected(op.Expected, tc.Comparison, opResult, opErr) {
+			f.Field = prefix + f.Field
+			failures = append(failures, f)
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+	result.Success = false
+	result.AssertionFailures = append(result.AssertionFailures, failures...)
+	result.Error = summarizeFailures(result.AssertionFailures)
+}
+
+// recordTeardownFailure 清理步骤失败时将测试标记为失败
+// EN: recordTeardownFailure marks the test as failed when a teardown step fails.
+func recordTeardownFailure(result *TestResult, err error) {
+	msg := fmt.Sprintf("Teardown 失败: %v", err) // EN: Teardown failed
+	result.Success = false
+	if result.Error == "" {
+		result.Error = msg
+	} else {
+		result.Error += "; " + msg
+	}
+}
+
+// checkExpected 检查每个已填写的预期字段，返回所有断言失败
+// EN: checkExpected checks every populated expected field and returns all assertion failures.
+func checkExpected(exp Expected, cmp *Comparison, result *TestResult, actionErr error) []AssertionFailure {
+	var failures []AssertionFailure
+
+	// 预期错误时只检查错误本身 // EN: When an error is expected, only the error itself is checked
+	if expectsError(exp) {
+		if actionErr == nil {
+			return []AssertionFailure{newFailure("error", expectedErrorValue(exp), nil)}
+		}
+		return checkError(exp, result, actionErr)
+	}
+	if actionErr != nil {
+		return []AssertionFailure{newFailure("error", nil, actionErr.Error())}
+	}
+
+	failures = appendCountFailure(failures, "count", exp.Count, result.Count)
+	failures = appendCountFailure(failures, "matched_count", exp.MatchedCount, result.MatchedCount)
+	failures = appendCountFailure(failures, "modified_count", exp.ModifiedCount, result.ModifiedCount)
+	failures = appendCountFailure(failures, "deleted_count", exp.DeletedCount, result.DeletedCount)
+
+	comparator := newComparator(cmp)
+	if exp.UpsertedID != nil {
+		failures = append(failures, comparator.compareValue("upserted_id", exp.UpsertedID, result.UpsertedID)...)
+	}
+
+	if exp.IndexName != "" && exp.IndexName != result.IndexName {
+		failures = append(failures, newFailure("index_name", exp.IndexName, result.IndexName))
+	}
+
+	if exp.Documents != nil {
+		ordered := cmp != nil && cmp.Ordered
+		failures = append(failures, comparator.compareResultSet("documents", exp.Documents, result.RawDocuments, ordered)...)
+	}
+
+	return failures
+}
+
+// expectsError 判断预期结果是否要求动作返回错误
+// EN: expectsError reports whether the expected result requires the action to fail.
+func expectsError(exp Expected) bool {
+	return exp.Error != "" || exp.ErrorCode != 0 || exp.ErrorCodeName != ""
+}
+
+// expectedErrorValue 返回断言失败中显示的预期错误：优先使用错误码名称，其次是错误码和错误信息
+// EN: expectedErrorValue returns the expected error shown in assertion failures: the code name first, then the code, then the message.
+func expectedErrorValue(exp Expected) any {
+	switch {
+	case exp.ErrorCodeName != "":
+		return exp.ErrorCodeName
+	case exp.ErrorCode != 0:
+		return exp.ErrorCode
+	default:
+		return exp.Error
+	}
+}
+
+// checkError 检查动作错误：用例给出错误码或错误码名称时按错误码比较，
+// 写错误只带数字错误码，因此优先比较双方都有的错误码；否则要求错误信息包含预期文本
+// EN: checkError checks the action error: when the case gives an error code or code name, the codes are compared,
+// EN: preferring the numeric code since write errors carry no code name; otherwise the message must contain the expected text.
+func checkError(exp Expected, result *TestResult, actionErr error) []AssertionFailure {
+	switch {
+	case exp.ErrorCode != 0 && result.ErrorCode != 0:
+		if exp.ErrorCode != result.ErrorCode {
+			return []AssertionFailure{newFailure("error_code", exp.ErrorCode, result.ErrorCode)}
+		}
+	case exp.ErrorCodeName != "" && result.ErrorCodeName != "":
+		if exp.ErrorCodeName != result.ErrorCodeName {
+			return []AssertionFailure{newFailure("error_code_name", exp.ErrorCodeName, result.ErrorCodeName)}
+		}
+	case exp.ErrorCode != 0 || exp.ErrorCodeName != "":
+		return []AssertionFailure{newFailure("error_code", expectedErrorValue(exp), actionErr.Error())}
+	default:
+		if !strings.Contains(actionErr.Error(), exp.Error) {
+			return []AssertionFailure{newFailure("error", exp.Error, actionErr.Error())}
+		}
+	}
+	return nil
+}
+
+// recordActionError 将动作错误及其错误码写入结果
+// EN: recordActionError records the action error and its code in the result.
+func recordActionError(result *TestResult, err error) {
+	result.Error = err.Error()
+	result.ActionError = err.Error()
+	result.ErrorCode, result.ErrorCodeName = errorCode(err)
+}
+
+// errorCode 提取错误中的错误码和错误码名称：API 模式的命令错误，或驱动返回的命令错误和写错误
+// EN: errorCode extracts the code and code name of an error: an API mode command error, or a command or write error returned by the driver.
+func errorCode(err error) (int32, string) {
+	var apiErr *commandError
+	var cmdErr mongo.CommandError
+	var writeErr mongo.WriteException
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Code, apiErr.CodeName
+	case errors.As(err, &cmdErr):
+		return cmdErr.Code, cmdErr.Name
+	case errors.As(err, &writeErr) && len(writeErr.WriteErrors) > 0:
+		return int32(writeErr.WriteErrors[0].Code), ""
+	case errors.As(err, &writeErr) && writeErr.WriteConcernError != nil:
+		return int32(writeErr.WriteConcernError.Code), writeErr.WriteConcernError.Name
+	}
+	return 0, ""
+}
+
+// newFailure 创建断言失败并记录双方的 BSON 类型
+// EN: newFailure creates an assertion failure and records the BSON type of both sides.
+func newFailure(field string, expected, actual any) AssertionFailure {
+	return AssertionFailure{
+		Field:        field,
+		Expected:     toPlainValue(expected),
+		Actual:       toPlainValue(actual),
+		ExpectedType: bsonTypeName(expected),
+		ActualType:   bsonTypeName(actual),
+	}
+}
+
+// String 返回断言失败的单行描述
+// EN: String returns a one-line description of the assertion failure.
+func (f AssertionFailure) String() string {
+	return fmt.Sprintf("%s: 预期 %s (%s), 实际 %s (%s)", // EN: %s: expected %s (%s), got %s (%s)
+		f.Field, formatValue(f.Expected), f.ExpectedType, formatValue(f.Actual), f.ActualType)
+}
+
+// summarizeFailures 将断言失败合并为错误描述
+// EN: summarizeFailures joins assertion failures into an error description.
+func summarizeFailures(failures []AssertionFailure) string {
+	parts := make([]string, len(failures))
+	for i, f := range failures {
+		parts[i] = f.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// appendCountFailure 比较数量字段，不一致时追加断言失败
+// EN: appendCountFailure compares a count field and appends an assertion failure on mismatch.
+func appendCountFailure(failures []AssertionFailure, field string, want *int64, got int64) []AssertionFailure {
+	if want == nil || *want == got {
+		return failures
+	}
+	return append(failures, newFailure(field, *want, got))
+}
+
+// formatValue 将值格式化为便于阅读的字符串
+// EN: formatValue formats a value as a readable string.
+func formatValue(v any) string {
+	if v == nil {
+		return "null"
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return string(raw)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// bsonTypeName 返回值对应的 BSON 类型别名（与 $type 一致）
+// EN: bsonTypeName returns the BSON type alias of a value, matching $type names.
+func bsonTypeName(v any) string {
+	if v == nil {
+		return "null"
+	}
+	t, _, err := bson.MarshalValue(v)
+	if err != nil {
+		return fmt.Sprintf("%T", v)
+	}
+	switch t {
+	case bsontype.Double:
+		return "double"
+	case bsontype.String:
+		return "string"
+	case bsontype.EmbeddedDocument:
+		return "object"
+	case bsontype.Array:
+		return "array"
+	case bsontype.Binary:
+		return "binData"
+	case bsontype.Undefined:
+		return "undefined"
+	case bsontype.ObjectID:
+		return "objectId"
+	case bsontype.Boolean:
+		return "bool"
+	case bsontype.DateTime:
+		return "date"
+	case bsontype.Null:
+		return "null"
+	case bsontype.Regex:
+		return "regex"
+	case bsontype.DBPointer:
+		return "dbPointer"
+	case bsontype.JavaScript:
+		return "javascript"
+	case bsontype.Symbol:
+		return "symbol"
+	case bsontype.CodeWithScope:
+		return "javascriptWithScope"
+	case bsontype.Int32:
+		return "int"
+	case bsontype.Timestamp:
+		return "timestamp"
+	case bsontype.Int64:
+		return "long"
+	case bsontype.Decimal128:
+		return "decimal"
+	case bsontype.MinKey:
+		return "minKey"
+	case bsontype.MaxKey:
+		return "maxKey"
+	default:
+		return t.String()
+	}
+}
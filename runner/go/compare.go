@@ -0,0 +1,375 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 比较模式 // EN: Comparison modes
+const (
+	CompareLenient = "lenient" // 数值等价、字段无序 // EN: Numeric equivalence, unordered fields
+	CompareStrict  = "strict"  // 类型精确、字段有序 // EN: Exact types, ordered fields
+)
+
+// extJSONWrapperKeys Extended JSON 类型包装键
+// EN: extJSONWrapperKeys lists the Extended JSON type wrapper keys.
+var extJSONWrapperKeys = map[string]bool{
+	"$oid": true, "$symbol": true, "$numberInt": true, "$numberLong": true,
+	"$numberDouble": true, "$numberDecimal": true, "$binary": true, "$uuid": true,
+	"$code": true, "$timestamp": true, "$regularExpression": true, "$dbPointer": true,
+	"$date": true, "$minKey": true, "$maxKey": true, "$undefined": true,
+}
+
+// documentComparator 深度比较 BSON 文档
+// EN: documentComparator deeply compares BSON documents.
+type documentComparator struct {
+	strict bool // 是否严格模式 // EN: Whether strict mode is used
+}
+
+// newComparator 根据测试用例的比较配置创建比较器
+// EN: newComparator creates a comparator from the comparison settings of a test case.
+func newComparator(c *Comparison) documentComparator {
+	return documentComparator{strict: c != nil && c.Mode == CompareStrict}
+}
+
+// compareResultSet 比较预期文档集与返回文档集
+// EN: compareResultSet compares the expected document set with the returned documents.
+func (c documentComparator) compareResultSet(path string, expected []any, actual []bson.D, ordered bool) []AssertionFailure {
+	if len(expected) != len(actual) {
+		return []AssertionFailure{newFailure(path+".length", int64(len(expected)), int64(len(actual)))}
+	}
+
+	if ordered {
+		var failures []AssertionFailure
+		for i := range expected {
+			failures = append(failures, c.compareValue(fmt.Sprintf("%s[%d]", path, i), expected[i], actual[i])...)
+		}
+		return failures
+	}
+
+	// 无序比较：为每个预期文档寻找一个完全匹配的返回文档
+	// EN: Unordered comparison: find a fully matching returned document for each expected document
+	used := make([]bool, len(actual))
+	var unmatched []int
+	for i, want := range expected {
+		found := false
+		for j, got := range actual {
+			if !used[j] && len(c.compareValue("", want, got)) == 0 {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, i)
+		}
+	}
+
+	// 未匹配的文档按顺序配对并报告差异 // EN: Pair unmatched documents in order and report their differences
+	var failures []AssertionFailure
+	j := 0
+	for _, i := range unmatched {
+		for used[j] {
+			j++
+		}
+		used[j] = true
+		failures = append(failures, c.compareValue(fmt.Sprintf("%s[%d]", path, i), expected[i], actual[j])...)
+	}
+	return failures
+}
+
+// compareValue 递归比较单个值
+// EN: compareValue recursively compares a single value.
+func (c documentComparator) compareValue(path string, want, got any) []AssertionFailure {
+	want = resolveExtJSON(want)
+	got = normalizeBSONValue(got)
+
+	switch w := want.(type) {
+	case bson.D:
+		return c.compareDocument(path, w, c.strict, got)
+	case bson.M:
+		return c.compareDocument(path, sortedDoc(w), false, got)
+	case map[string]any:
+		return c.compareDocument(path, sortedDoc(w), false, got)
+	case bson.A:
+		return c.compareArray(path, w, got)
+	case []any:
+		return c.compareArray(path, w, got)
+	}
+
+	if c.scalarEqual(want, got) {
+		return nil
+	}
+	return []AssertionFailure{newFailure(path, want, got)}
+}
+
+// compareDocument 比较子文档，ordered 为真时检查字段顺序
+// EN: compareDocument compares embedded documents and checks field order when ordered is true.
+func (c documentComparator) compareDocument(path string, want bson.D, ordered bool, gotRaw any) []AssertionFailure {
+	got, ok := asDocument(gotRaw)
+	if !ok {
+		return []AssertionFailure{newFailure(path, want, gotRaw)}
+	}
+
+	var failures []AssertionFailure
+	for _, e := range want {
+		fieldPath := joinPath(path, e.Key)
+		v, found := lookupField(got, e.Key)
+		if !found {
+			failures = append(failures, missingFailure(fieldPath, e.Value))
+			continue
+		}
+		failures = append(failures, c.compareValue(fieldPath, e.Value, v)...)
+	}
+	for _, e := range got {
+		if _, found := lookupField(want, e.Key); !found {
+			failure := newFailure(joinPath(path, e.Key), nil, e.Value)
+			failure.ExpectedType = "missing"
+			failures = append(failures, failure)
+		}
+	}
+
+	if ordered && len(failures) == 0 && !sameKeyOrder(want, got) {
+		failures = append(failures, newFailure(joinPath(path, "(field order)"), documentKeys(want), documentKeys(got)))
+	}
+	return failures
+}
+
+// compareArray 按顺序比较数组元素
+// EN: compareArray compares array elements in order.
+func (c documentComparator) compareArray(path string, want []any, gotRaw any) []AssertionFailure {
+	got, ok := asArray(gotRaw)
+	if !ok {
+		return []AssertionFailure{newFailure(path, want, gotRaw)}
+	}
+	if len(want) != len(got) {
+		return []AssertionFailure{newFailure(path+".length", int64(len(want)), int64(len(got)))}
+	}
+
+	var failures []AssertionFailure
+	for i := range want {
+		failures = append(failures, c.compareValue(fmt.Sprintf("%s[%d]", path, i), want[i], got[i])...)
+	}
+	return failures
+}
+
+// scalarEqual 比较标量值；宽松模式下数值按等价比较
+// EN: scalarEqual compares scalar values; numbers compare by equivalence in lenient mode.
+func (c documentComparator) scalarEqual(want, got any) bool {
+	if c.strict && bsonTypeName(want) != bsonTypeName(got) {
+		return false
+	}
+	// 严格模式下 Decimal128 需保留精度与尾随零，如 1.0 与 1.00 不相等
+	// EN: In strict mode Decimal128 must keep precision and trailing zeros, e.g. 1.0 differs from 1.00
+	if wd, ok := want.(primitive.Decimal128); ok && c.strict {
+		gd, _ := got.(primitive.Decimal128)
+		return wd.String() == gd.String()
+	}
+
+	wn, wIsNum := numericValue(want)
+	gn, gIsNum := numericValue(got)
+	if wIsNum && gIsNum {
+		if math.IsNaN(wn.f) && math.IsNaN(gn.f) {
+			return true
+		}
+		if wn.isInt && gn.isInt {
+			return wn.i == gn.i
+		}
+		return wn.f == gn.f
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+// numeric 统一表示的数值
+// EN: numeric is a unified numeric representation.
+type numeric struct {
+	i     int64   // 整数值 // EN: Integer value
+	f     float64 // 浮点值 // EN: Floating point value
+	isInt bool    // 是否为精确整数 // EN: Whether the value is an exact integer
+}
+
+// numericValue 将数值类型转换为统一表示
+// EN: numericValue converts a numeric type to the unified representation.
+func numericValue(v any) (numeric, bool) {
+	switch n := v.(type) {
+	case int:
+		return numeric{i: int64(n), f: float64(n), isInt: true}, true
+	case int32:
+		return numeric{i: int64(n), f: float64(n), isInt: true}, true
+	case int64:
+		return numeric{i: n, f: float64(n), isInt: true}, true
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return numeric{i: int64(n), f: n, isInt: true}, true
+		}
+		return numeric{f: n}, true
+	case primitive.Decimal128:
+		f, err := strconv.ParseFloat(n.String(), 64)
+		if err != nil {
+			return numeric{f: math.NaN()}, true
+		}
+		return numericValue(f)
+	default:
+		return numeric{}, false
+	}
+}
+
+// resolveExtJSON 将 Extended JSON 包装（如 {"$numberInt": "1"}）解析为 BSON 类型值
+// EN: resolveExtJSON resolves Extended JSON wrappers such as {"$numberInt": "1"} into typed BSON values.
+func resolveExtJSON(v any) any {
+	var key string
+	switch d := v.(type) {
+	case map[string]any:
+		if len(d) != 1 {
+			return v
+		}
+		for k := range d {
+			key = k
+		}
+	case bson.D:
+		if len(d) != 1 {
+			return v
+		}
+		key = d[0].Key
+	default:
+		return v
+	}
+	if !extJSONWrapperKeys[key] {
+		return v
+	}
+
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, false, false)
+	if err != nil {
+		return v
+	}
+	var wrapped struct {
+		V any `bson:"v"`
+	}
+	if err := bson.UnmarshalExtJSON(data, false, &wrapped); err != nil {
+		return v
+	}
+	return wrapped.V
+}
+
+// normalizeBSONValue 将引擎和驱动返回的等价类型统一
+// EN: normalizeBSONValue unifies equivalent types returned by the engine and the driver.
+func normalizeBSONValue(v any) any {
+	switch val := v.(type) {
+	case time.Time:
+		return primitive.NewDateTimeFromTime(val)
+	case []byte:
+		return primitive.Binary{Data: val}
+	default:
+		return v
+	}
+}
+
+// asDocument 将返回值视为文档
+// EN: asDocument treats a returned value as a document.
+func asDocument(v any) (bson.D, bool) {
+	switch d := v.(type) {
+	case bson.D:
+		return d, true
+	case bson.M:
+		return sortedDoc(d), true
+	case map[string]any:
+		return sortedDoc(d), true
+	case bson.Raw:
+		var doc bson.D
+		if err := bson.Unmarshal(d, &doc); err != nil {
+			return nil, false
+		}
+		return doc, true
+	default:
+		return nil, false
+	}
+}
+
+// asArray 将返回值视为数组
+// EN: asArray treats a returned value as an array.
+func asArray(v any) ([]any, bool) {
+	switch a := v.(type) {
+	case bson.A:
+		return a, true
+	case []any:
+		return a, true
+	default:
+		return nil, false
+	}
+}
+
+// sortedDoc 将无序 map 转换为按键排序的 bson.D
+// EN: sortedDoc converts an unordered map into a bson.D sorted by key.
+func sortedDoc(m map[string]any) bson.D {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	doc := make(bson.D, 0, len(keys))
+	for _, k := range keys {
+		doc = append(doc, bson.E{Key: k, Value: m[k]})
+	}
+	return doc
+}
+
+// lookupField 在文档中查找字段
+// EN: lookupField looks up a field in a document.
+func lookupField(doc bson.D, key string) (any, bool) {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// sameKeyOrder 判断两个文档的字段顺序是否一致
+// EN: sameKeyOrder reports whether two documents have the same field order.
+func sameKeyOrder(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key {
+			return false
+		}
+	}
+	return true
+}
+
+// documentKeys 返回文档的字段名列表
+// EN: documentKeys returns the field names of a document.
+func documentKeys(doc bson.D) []string {
+	keys := make([]string, len(doc))
+	for i, e := range doc {
+		keys[i] = e.Key
+	}
+	return keys
+}
+
+// joinPath 拼接字段路径
+// EN: joinPath joins a field path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// missingFailure 创建字段缺失的断言失败
+// EN: missingFailure creates an assertion failure for a missing field.
+func missingFailure(path string, want any) AssertionFailure {
+	failure := newFailure(path, want, nil)
+	failure.ActualType = "missing"
+	return failure
+}
@@ -0,0 +1,173 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mustDecimal 解析 Decimal128 字面量 // EN: mustDecimal parses a Decimal128 literal
+func mustDecimal(t *testing.T, s string) primitive.Decimal128 {
+	t.Helper()
+	d, err := primitive.ParseDecimal128(s)
+	if err != nil {
+		t.Fatalf("ParseDecimal128(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestCompareValue(t *testing.T) {
+	nan := math.NaN()
+	negZero := math.Copysign(0, -1)
+
+	tests := []struct {
+		name    string
+		want    any
+		got     any
+		lenient bool // 宽松模式下是否相等 // EN: Whether the values are equal in lenient mode
+		strict  bool // 严格模式下是否相等 // EN: Whether the values are equal in strict mode
+	}{
+		{"same int32", int32(1), int32(1), true, true},
+		{"int32 vs int64", int32(1), int64(1), true, false},
+		{"int32 vs whole double", int32(1), float64(1), true, false},
+		{"int64 vs fractional double", int64(1), 1.5, false, false},
+		{"different ints", int32(1), int32(2), false, false},
+		{"large int64 precision", int64(1<<53 + 1), int64(1 << 53), false, false},
+		{"NaN equals NaN", nan, nan, true, true},
+		{"NaN vs number", nan, 1.0, false, false},
+		{"positive vs negative zero", 0.0, negZero, true, true},
+		{"int zero vs negative zero", int32(0), negZero, true, false},
+		{"decimal vs double", mustDecimal(t, "1.5"), 1.5, true, false},
+		{"decimal trailing zeros", mustDecimal(t, "1.0"), mustDecimal(t, "1.00"), true, false},
+		{"same decimal", mustDecimal(t, "2.50"), mustDecimal(t, "2.50"), true, true},
+		{"Extended JSON wrapper", bson.D{{Key: "$numberLong", Value: "7"}}, int64(7), true, true},
+		{"Extended JSON wrapper vs int32", bson.D{{Key: "$numberLong", Value: "7"}}, int32(7), true, false},
+		{"string", "a", "a", true, true},
+		{"string vs number", "1", int32(1), false, false},
+		{"same field order", bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: int32(2)}},
+			bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: int32(2)}}, true, true},
+		{"different field order", bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: int32(2)}},
+			bson.D{{Key: "b", Value: int32(2)}, {Key: "a", Value: int32(1)}}, true, false},
+		{"map ignores field order", bson.M{"a": int32(1), "b": int32(2)},
+			bson.D{{Key: "b", Value: int32(2)}, {Key: "a", Value: int32(1)}}, true, true},
+		{"missing field", bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: int32(2)}},
+			bson.D{{Key: "a", Value: int32(1)}}, false, false},
+		{"extra field", bson.D{{Key: "a", Value: int32(1)}},
+			bson.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: int32(2)}}, false, false},
+		{"nested numeric types", bson.D{{Key: "a", Value: bson.A{int32(1), bson.D{{Key: "b", Value: int64(2)}}}}},
+			bson.D{{Key: "a", Value: bson.A{float64(1), bson.D{{Key: "b", Value: int32(2)}}}}}, true, false},
+		{"array order matters", bson.A{int32(1), int32(2)}, bson.A{int32(2), int32(1)}, false, false},
+		{"array length", bson.A{int32(1)}, bson.A{int32(1), int32(1)}, false, false},
+		{"document vs scalar", bson.D{{Key: "a", Value: int32(1)}}, int32(1), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, mode := range []struct {
+				cmp  *Comparison
+				want bool
+			}{
+				{nil, tt.lenient},
+				{&Comparison{Mode: CompareStrict}, tt.strict},
+			} {
+				failures := newComparator(mode.cmp).compareValue("v", tt.want, tt.got)
+				if equal := len(failures) == 0; equal != mode.want {
+					t.Errorf("strict=%v: equal = %v, want %v (failures: %v)", mode.cmp != nil, equal, mode.want, failures)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareResultSet(t *testing.T) {
+	doc := func(id int32, v any) bson.D { return bson.D{{Key: "_id", Value: id}, {Key: "v", Value: v}} }
+
+	tests := []struct {
+		name     string
+		expected []any
+		actual   []bson.D
+		ordered  bool
+		fields   []string // 预期的断言失败字段 // EN: Expected assertion failure fields
+	}{
+		{
+			name:     "ordered match",
+			expected: []any{doc(1, "a"), doc(2, "b")},
+			actual:   []bson.D{doc(1, "a"), doc(2, "b")},
+			ordered:  true,
+		},
+		{
+			name:     "ordered reports wrong order",
+			expected: []any{doc(1, "a"), doc(2, "b")},
+			actual:   []bson.D{doc(2, "b"), doc(1, "a")},
+			ordered:  true,
+			fields:   []string{"docs[0]._id", "docs[0].v", "docs[1]._id", "docs[1].v"},
+		},
+		{
+			name:     "unordered pairs any order",
+			expected: []any{doc(1, "a"), doc(2, "b"), doc(3, "c")},
+			actual:   []bson.D{doc(3, "c"), doc(1, "a"), doc(2, "b")},
+		},
+		{
+			name:     "unordered pairs duplicates once",
+			expected: []any{doc(1, "a"), doc(1, "a")},
+			actual:   []bson.D{doc(1, "a"), doc(1, "b")},
+			fields:   []string{"docs[1].v"},
+		},
+		{
+			name:     "unordered reports the unmatched document",
+			expected: []any{doc(1, "a"), doc(2, "b")},
+			actual:   []bson.D{doc(2, "b"), doc(1, "x")},
+			fields:   []string{"docs[0].v"},
+		},
+		{
+			name:     "length mismatch",
+			expected: []any{doc(1, "a")},
+			actual:   []bson.D{doc(1, "a"), doc(2, "b")},
+			fields:   []string{"docs.length"},
+		},
+		{
+			name:     "empty",
+			expected: []any{},
+			actual:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := newComparator(nil).compareResultSet("docs", tt.expected, tt.actual, tt.ordered)
+			var fields []string
+			for _, f := range failures {
+				fields = append(fields, f.Field)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("failures = %v, want fields %v", fields, tt.fields)
+			}
+			for i := range fields {
+				if fields[i] != tt.fields[i] {
+					t.Errorf("failure %d field = %q, want %q", i, fields[i], tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompareFailureTypes(t *testing.T) {
+	strict := newComparator(&Comparison{Mode: CompareStrict})
+
+	failures := strict.compareValue("n", int32(1), int64(1))
+	if len(failures) != 1 {
+		t.Fatalf("failures = %v, want 1", failures)
+	}
+	if f := failures[0]; f.ExpectedType != "int" || f.ActualType != "long" {
+		t.Errorf("types = %s/%s, want int/long", f.ExpectedType, f.ActualType)
+	}
+
+	failures = strict.compareValue("", bson.D{{Key: "a", Value: int32(1)}}, bson.D{})
+	if len(failures) != 1 || failures[0].ActualType != "missing" {
+		t.Errorf("missing field failure = %v, want actual type missing", failures)
+	}
+}
@@ -0,0 +1,300 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiffRunner 差异模式运行器：在两份相同的数据库副本上分别通过 APIRunner 和 WireRunner 运行同一个测试，
+// 比较两者的数量、文档、BSON 类型和错误，而不关心哪一方符合预期结果；
+// 断言失败中的 expected 为 API 结果，actual 为 Wire 结果
+// EN: DiffRunner runs each test through APIRunner and WireRunner against two identical database copies
+// EN: and compares their counts, documents, BSON types and errors, regardless of which one matches the expected result;
+// EN: in the assertion failures, expected holds the API result and actual holds the wire result.
+type DiffRunner struct {
+	pairs   []*diffPair    // 所有运行器对 // EN: All runner pairs
+	pool    chan *diffPair // 空闲的运行器对 // EN: Idle runner pairs
+	tempDir string         // 运行器对的数据库副本所在的临时目录 // EN: Temporary directory holding the copies of the runner pairs
+}
+
+// diffPair 一对在相同数据库副本上运行的 API 和 Wire 运行器；同一时间只运行一个测试，两个副本因此保持同步。
+// 复用的副本中保留着先前测试生成的 ObjectId，因此 ids 在整个生命周期内累积配对；隔离模式下为 nil，每个测试单独配对
+// EN: diffPair is an API and a wire runner running against identical database copies; it runs one test at a time, which keeps both copies in step.
+// EN: Reused copies keep the ObjectIds generated by earlier tests, so ids accumulates the pairs over the whole lifetime;
+// EN: it is nil in isolated mode, where every test is paired on its own.
+type diffPair struct {
+	api  Runner                                    // API 模式运行器 // EN: API mode runner
+	wire Runner                                    // Wire 模式运行器 // EN: Wire mode runner
+	ids  map[primitive.ObjectID]primitive.ObjectID // Wire 结果到 API 结果的 ObjectId 配对 // EN: ObjectId pairs from the wire result to the API result
+}
+
+// NewDiffRunner 创建差异模式运行器。默认每个工作协程一对运行器，各自在 sourcePath 的两份副本上运行并在测试之间复用，
+// 从而只为每个工作协程启动一个 Wire 服务器；isolated 为 true 时每个测试都在全新副本上运行
+// EN: NewDiffRunner creates a diff mode runner. By default every worker gets a runner pair on two copies of sourcePath that is reused across tests,
+// EN: so only one wire server is started per worker; when isolated is true every test runs against fresh copies instead.
+func NewDiffRunner(sourcePath string, workers int, isolated bool) (*DiffRunner, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	r := &DiffRunner{pool: make(chan *diffPair, workers)}
+
+	if isolated {
+		api, err := NewIsolatedRunner(sourcePath, "api", newAPIRunner)
+		if err != nil {
+			return nil, err
+		}
+		wire, err := NewIsolatedRunner(sourcePath, "wire", newWireRunner)
+		if err != nil {
+			api.Close()
+			return nil, err
+		}
+		// 隔离运行器可被多个协程同时使用，同一对运行器在池中放入多次
+		// EN: Isolated runners can be used from several goroutines at once, so the same pair is put into the pool several times
+		pair := &diffPair{api: api, wire: wire}
+		r.pairs = []*diffPair{pair}
+		for i := 0; i < workers; i++ {
+			r.pool <- pair
+		}
+		return r, nil
+	}
+
+	if _, err := os.Stat(sourcePath); err != nil {
+		return nil, fmt.Errorf("数据库文件不可用: %w", err) // EN: Database file unavailable
+	}
+	tempDir, err := os.MkdirTemp("", "monolite-diff-")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err) // EN: Failed to create temporary directory
+	}
+	r.tempDir = tempDir
+	for i := 0; i < workers; i++ {
+		pair, err := r.newPair(sourcePath)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.pairs = append(r.pairs, pair)
+		r.pool <- pair
+	}
+	return r, nil
+}
+
+// newPair 复制两份数据库，并在副本上分别创建 API 和 Wire 运行器
+// EN: newPair copies the database twice and creates an API and a wire runner on the copies.
+func (r *DiffRunner) newPair(sourcePath string) (*diffPair, error) {
+	var paths [2]string
+	for i := range paths {
+		dir, err := os.MkdirTemp(r.tempDir, "copy-")
+		if err != nil {
+			return nil, fmt.Errorf("创建临时目录失败: %w", err) // EN: Failed to create temporary directory
+		}
+		paths[i] = filepath.Join(dir, filepath.Base(sourcePath))
+		if err := copyDatabase(sourcePath, paths[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	api, err := newAPIRunner(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	wire, err := newWireRunner(paths[1])
+	if err != nil {
+		api.Close()
+		return nil, err
+	}
+	return &diffPair{api: api, wire: wire, ids: make(map[primitive.ObjectID]primitive.ObjectID)}, nil
+}
+
+// Close 关闭所有运行器并删除数据库副本；超时的 API 测试仍在使用副本时保留临时目录
+// EN: Close closes every runner and removes the database copies; the temporary directory is kept while a timed-out API test is still using its copy.
+func (r *DiffRunner) Close() error {
+	var firstErr error
+	keep := false
+	for _, pair := range r.pairs {
+		if a, ok := pair.api.(abandoner); ok && a.Abandoned() != nil {
+			keep = true
+		}
+		for _, runner := range []Runner{pair.api, pair.wire} {
+			if err := runner.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if r.tempDir == "" {
+		return firstErr
+	}
+	if keep {
+		log.Printf("警告: 超时的测试仍在运行，保留临时目录 %s", r.tempDir) // EN: Warning: a timed-out test is still running, temporary directory kept
+		return firstErr
+	}
+	if err := os.RemoveAll(r.tempDir); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// RunTest 依次以两种模式运行测试并比较结果；没有差异时测试通过
+// EN: RunTest runs the test in both modes one after the other and compares the results; the test passes when they do not diverge.
+func (r *DiffRunner) RunTest(tc TestCase) TestResult {
+	start := time.Now()
+	pair := <-r.pool
+	apiResult := pair.api.RunTest(tc)
+	wireResult := pair.wire.RunTest(tc)
+
+	result := TestResult{
+		TestName: tc.Name,
+		Language: "go",
+		Mode:     "diff",
+	}
+	// 复用的运行器对沿用先前测试的配对，配对完成前不能归还 // EN: A reused pair keeps the pairs of earlier tests and is only returned once pairing is done
+	ids := pair.ids
+	if ids == nil {
+		ids = make(map[primitive.ObjectID]primitive.ObjectID)
+	}
+	pairGeneratedIDs(apiResult, wireResult, ids)
+	failures := diffResults("", apiResult, wireResult, tc.Comparison != nil && tc.Comparison.Ordered, ids)
+	r.pool <- pair
+
+	result.Duration = time.Since(start).Milliseconds()
+	result.Success = len(failures) == 0
+	if !result.Success {
+		result.AssertionFailures = failures
+		result.Error = summarizeFailures(failures)
+	}
+	return result
+}
+
+// diffResults 比较两种模式的结果，返回所有差异；path 为事务操作或场景步骤的字段前缀
+// EN: diffResults compares the results of both modes and returns every divergence; path is the field prefix of a transaction operation or scenario step.
+func diffResults(path string, api, wire TestResult, ordered bool, ids map[primitive.ObjectID]primitive.ObjectID) []AssertionFailure {
+	// 两种模式的错误信息格式不同（Wire 模式带有驱动的包装信息），只比较是否出错；双方都有错误码时比较错误码
+	// EN: Error messages are formatted differently in each mode (wire errors carry the driver's wrapping),
+	// EN: so only the presence of an error is compared, plus the codes when both sides carry one
+	if api.ActionError != "" || wire.ActionError != "" {
+		if api.ActionError == "" || wire.ActionError == "" {
+			return []AssertionFailure{newFailure(path+"error", nilIfEmpty(api.ActionError), nilIfEmpty(wire.ActionError))}
+		}
+		if api.ErrorCode != 0 && wire.ErrorCode != 0 && api.ErrorCode != wire.ErrorCode {
+			return []AssertionFailure{newFailure(path+"error_code", api.ErrorCode, wire.ErrorCode)}
+		}
+		return nil
+	}
+
+	var failures []AssertionFailure
+	failures = appendCountFailure(failures, path+"count", &api.Count, wire.Count)
+	failures = appendCountFailure(failures, path+"matched_count", &api.MatchedCount, wire.MatchedCount)
+	failures = appendCountFailure(failures, path+"modified_count", &api.ModifiedCount, wire.ModifiedCount)
+	failures = appendCountFailure(failures, path+"deleted_count", &api.DeletedCount, wire.DeletedCount)
+
+	// 严格比较，BSON 类型和字段顺序的差异同样是语义差异
+	// EN: Compare strictly: differences in BSON types and field order are semantic divergences too
+	comparator := newComparator(&Comparison{Mode: CompareStrict})
+	if api.UpsertedID != nil || wire.UpsertedID != nil {
+		failures = append(failures, comparator.compareValue(path+"upserted_id", api.UpsertedID, replaceIDs(wire.UpsertedID, ids))...)
+	}
+	if api.IndexName != wire.IndexName {
+		failures = append(failures, newFailure(path+"index_name", api.IndexName, wire.IndexName))
+	}
+	failures = append(failures, comparator.compareResultSet(path+"documents", docsToAny(api.RawDocuments), replaceDocIDs(wire.RawDocuments, ids), ordered)...)
+	failures = append(failures, comparator.compareResultSet(path+"verify_documents", docsToAny(api.RawVerifyDocuments), replaceDocIDs(wire.RawVerifyDocuments, ids), ordered)...)
+
+	if len(api.Operations) != len(wire.Operations) {
+		return append(failures, newFailure(path+"operations.length", int64(len(api.Operations)), int64(len(wire.Operations))))
+	}
+	for i := range api.Operations {
+		failures = append(failures, diffResults(fmt.Sprintf("%soperations[%d].", path, i), api.Operations[i], wire.Operations[i], ordered, ids)...)
+	}
+	return failures
+}
+
+// pairGeneratedIDs 按插入顺序将 Wire 结果中的 ObjectId 与 API 结果中对应位置的 ObjectId 配对；
+// 两次运行各自生成 _id，替换后才能比较返回的文档
+// EN: pairGeneratedIDs pairs the ObjectIds of the wire result with those at the same position in the API result, in insertion order;
+// EN: each run generates its own _id values, which must be substituted before the returned documents can be compared.
+func pairGeneratedIDs(api, wire TestResult, ids map[primitive.ObjectID]primitive.ObjectID) {
+	for i := 0; i < len(api.InsertedIDs) && i < len(wire.InsertedIDs); i++ {
+		pairID(api.InsertedIDs[i], wire.InsertedIDs[i], ids)
+	}
+	pairID(api.UpsertedID, wire.UpsertedID, ids)
+	for i := 0; i < len(api.Operations) && i < len(wire.Operations); i++ {
+		pairGeneratedIDs(api.Operations[i], wire.Operations[i], ids)
+	}
+}
+
+// pairID 两个值都是 ObjectId 时记录配对
+// EN: pairID records a pair when both values are ObjectIds.
+func pairID(api, wire any, ids map[primitive.ObjectID]primitive.ObjectID) {
+	apiID, ok := api.(primitive.ObjectID)
+	if !ok {
+		return
+	}
+	if wireID, ok := wire.(primitive.ObjectID); ok {
+		ids[wireID] = apiID
+	}
+}
+
+// replaceIDs 将值中已配对的 ObjectId 替换为 API 结果中的 ObjectId，原值不会被修改
+// EN: replaceIDs substitutes the paired ObjectIds in a value with their API counterparts; the original value is left untouched.
+func replaceIDs(v any, ids map[primitive.ObjectID]primitive.ObjectID) any {
+	switch val := v.(type) {
+	case primitive.ObjectID:
+		if id, ok := ids[val]; ok {
+			return id
+		}
+		return val
+	case bson.D:
+		out := make(bson.D, len(val))
+		for i, e := range val {
+			out[i] = bson.E{Key: e.Key, Value: replaceIDs(e.Value, ids)}
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(val))
+		for i, item := range val {
+			out[i] = replaceIDs(item, ids)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// replaceDocIDs 对每个文档执行 replaceIDs
+// EN: replaceDocIDs applies replaceIDs to every document.
+func replaceDocIDs(docs []bson.D, ids map[primitive.ObjectID]primitive.ObjectID) []bson.D {
+	if len(ids) == 0 {
+		return docs
+	}
+	out := make([]bson.D, len(docs))
+	for i, d := range docs {
+		out[i] = replaceIDs(d, ids).(bson.D)
+	}
+	return out
+}
+
+// docsToAny 将文档列表转换为 compareResultSet 所需的预期值列表
+// EN: docsToAny converts a document list into the expected values compareResultSet takes.
+func docsToAny(docs []bson.D) []any {
+	out := make([]any, len(docs))
+	for i, d := range docs {
+		out[i] = d
+	}
+	return out
+}
+
+// nilIfEmpty 空字符串返回 nil，使断言失败中的缺失错误显示为 null
+// EN: nilIfEmpty returns nil for an empty string so a missing error shows as null in the assertion failure.
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
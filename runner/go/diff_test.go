@@ -0,0 +1,47 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scriptedRunner 按测试名称返回预先设定的结果 // EN: scriptedRunner returns a preset result per test name
+type scriptedRunner map[string]TestResult
+
+func (r scriptedRunner) RunTest(tc TestCase) TestResult { return r[tc.Name] }
+func (r scriptedRunner) Close() error                   { return nil }
+
+func TestDiffRunnerPairsIDsAcrossTests(t *testing.T) {
+	apiID, wireID := primitive.NewObjectID(), primitive.NewObjectID()
+	api := scriptedRunner{
+		"insert": {InsertedIDs: []any{apiID}},
+		"find":   {RawDocuments: []bson.D{{{Key: "_id", Value: apiID}}}},
+	}
+	wire := scriptedRunner{
+		"insert": {InsertedIDs: []any{wireID}},
+		"find":   {RawDocuments: []bson.D{{{Key: "_id", Value: wireID}}}},
+	}
+
+	tests := []struct {
+		name    string
+		pair    *diffPair
+		success bool
+	}{
+		{"reused pair keeps earlier pairs", &diffPair{api: api, wire: wire, ids: make(map[primitive.ObjectID]primitive.ObjectID)}, true},
+		{"pair without ids pairs each test on its own", &diffPair{api: api, wire: wire}, false},
+	}
+	for _, tt := range tests {
+		r := &DiffRunner{pairs: []*diffPair{tt.pair}, pool: make(chan *diffPair, 1)}
+		r.pool <- tt.pair
+		if result := r.RunTest(TestCase{Name: "insert"}); !result.Success {
+			t.Fatalf("%s: insert diverged: %v", tt.name, result.AssertionFailures)
+		}
+		if result := r.RunTest(TestCase{Name: "find"}); result.Success != tt.success {
+			t.Errorf("%s: find success = %v, want %v (failures: %v)", tt.name, result.Success, tt.success, result.AssertionFailures)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// testFilter 测试筛选条件
+// EN: testFilter selects which test cases are run.
+type testFilter struct {
+	run        *regexp.Regexp  // 名称必须匹配 // EN: Name must match
+	skip       *regexp.Regexp  // 名称匹配则跳过 // EN: Skip when the name matches
+	categories map[string]bool // 允许的分类 // EN: Allowed categories
+	methods    map[string]bool // 允许的动作方法 // EN: Allowed action methods
+}
+
+// newTestFilter 根据命令行参数构造筛选条件
+// EN: newTestFilter builds the filter from the command line arguments.
+func newTestFilter(run, skip, categories, methods string) (*testFilter, error) {
+	f := &testFilter{
+		categories: splitList(categories),
+		methods:    splitList(methods),
+	}
+	var err error
+	if run != "" {
+		if f.run, err = regexp.Compile(run); err != nil {
+			return nil, fmt.Errorf("--run: %w", err)
+		}
+	}
+	if skip != "" {
+		if f.skip, err = regexp.Compile(skip); err != nil {
+			return nil, fmt.Errorf("--skip: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// skipReason 返回测试被跳过的原因；返回空字符串表示需要运行
+// EN: skipReason returns why a test is skipped; an empty string means it should run.
+func (f *testFilter) skipReason(tc TestCase) string {
+	switch {
+	case f.run != nil && !f.run.MatchString(tc.Name):
+		return fmt.Sprintf("名称不匹配 --run %s", f.run) // EN: Name does not match --run
+	case f.skip != nil && f.skip.MatchString(tc.Name):
+		return fmt.Sprintf("名称匹配 --skip %s", f.skip) // EN: Name matches --skip
+	case len(f.categories) > 0 && !f.categories[tc.Category]:
+		return fmt.Sprintf("分类 %s 未被选中", tc.Category) // EN: Category not selected
+	case len(f.methods) > 0 && !f.methods[tc.Action.Method]:
+		return fmt.Sprintf("方法 %s 未被选中", tc.Action.Method) // EN: Method not selected
+	default:
+		return ""
+	}
+}
+
+// skippedResult 构造被跳过测试的结果
+// EN: skippedResult builds the result of a skipped test.
+func skippedResult(tc TestCase, mode, reason string) TestResult {
+	return TestResult{
+		TestName:   tc.Name,
+		Language:   "go",
+		Mode:       mode,
+		Status:     StatusSkipped,
+		SkipReason: reason,
+	}
+}
+
+// resultStatus 根据执行结果确定测试状态
+// EN: resultStatus derives the test status from the execution result.
+func resultStatus(result TestResult) string {
+	if result.Status != "" {
+		return result.Status
+	}
+	if result.Success {
+		return StatusPassed
+	}
+	return StatusFailed
+}
+
+// splitList 将逗号分隔的列表转换为集合
+// EN: splitList converts a comma separated list into a set.
+func splitList(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,91 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSkipReason(t *testing.T) {
+	insert := TestCase{Name: "insert_single_doc", Category: "crud", Action: TestAction{Method: "insertOne"}}
+	match := TestCase{Name: "query_eq", Category: "query", Action: TestAction{Method: "find"}}
+
+	tests := []struct {
+		name       string
+		run        string
+		skip       string
+		categories string
+		methods    string
+		tc         TestCase
+		skipped    bool
+	}{
+		{name: "no filter", tc: insert},
+		{name: "run matches", run: "^insert_", tc: insert},
+		{name: "run does not match", run: "^insert_", tc: match, skipped: true},
+		{name: "skip matches", skip: "single", tc: insert, skipped: true},
+		{name: "skip does not match", skip: "single", tc: match},
+		{name: "skip wins over run", run: "insert", skip: "insert_single", tc: insert, skipped: true},
+		{name: "category selected", categories: "query, crud", tc: insert},
+		{name: "category not selected", categories: "query", tc: insert, skipped: true},
+		{name: "method selected", methods: "find,insertOne", tc: insert},
+		{name: "method not selected", methods: "find", tc: insert, skipped: true},
+		{name: "all conditions must hold", run: "query", categories: "query", methods: "aggregate", tc: match, skipped: true},
+		{name: "empty list items are ignored", categories: " , ", tc: match},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newTestFilter(tt.run, tt.skip, tt.categories, tt.methods)
+			if err != nil {
+				t.Fatalf("newTestFilter: %v", err)
+			}
+			reason := f.skipReason(tt.tc)
+			if skipped := reason != ""; skipped != tt.skipped {
+				t.Errorf("skipReason = %q, want skipped %v", reason, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestNewTestFilterInvalidPattern(t *testing.T) {
+	for _, args := range [][2]string{{"(", ""}, {"", "["}} {
+		if _, err := newTestFilter(args[0], args[1], "", ""); err == nil {
+			t.Errorf("newTestFilter(%q, %q) succeeded, want error", args[0], args[1])
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]bool
+	}{
+		{"", map[string]bool{}},
+		{"crud", map[string]bool{"crud": true}},
+		{" crud , query,,crud ", map[string]bool{"crud": true, "query": true}},
+	}
+	for _, tt := range tests {
+		if got := splitList(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitList(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResultStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result TestResult
+		want   string
+	}{
+		{"passed", TestResult{Success: true}, StatusPassed},
+		{"failed", TestResult{}, StatusFailed},
+		{"skipped keeps status", skippedResult(TestCase{Name: "x"}, "api", "filtered"), StatusSkipped},
+		{"timeout keeps status", TestResult{Status: StatusTimeout}, StatusTimeout},
+	}
+	for _, tt := range tests {
+		if got := resultStatus(tt.result); got != tt.want {
+			t.Errorf("%s: resultStatus = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/monolite/monolite-test/reference"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExpectedResult 参考结果（MongoDB 记录或黄金文件），格式与生成器共用
+// EN: ExpectedResult is a reference result (MongoDB recording or golden file); the format is shared with the generator.
+type ExpectedResult = reference.ExpectedResult
+
+// loadReferences 为测试用例加载参考结果，返回加载数量
+// EN: loadReferences loads reference results for the test cases and returns how many were loaded.
+func loadReferences(dir string, suite *TestSuite) (int, error) {
+	loaded := 0
+	for i := range suite.Tests {
+		ref, err := reference.Read(reference.Path(dir, suite.Tests[i].Name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return loaded, fmt.Errorf("读取参考结果失败 %s: %w", suite.Tests[i].Name, err) // EN: Failed to read reference result
+		}
+		suite.Tests[i].Reference = ref
+		loaded++
+	}
+	return loaded, nil
+}
+
+// hasGoldenReferences 判断是否加载了黄金文件
+// EN: hasGoldenReferences reports whether any golden file was loaded.
+func hasGoldenReferences(suite *TestSuite) bool {
+	for _, tc := range suite.Tests {
+		if tc.Reference != nil && tc.Reference.Source == reference.SourceGolden {
+			return true
+		}
+	}
+	return false
+}
+
+// saveGolden 将当前 MonoLite 结果写入黄金文件；MongoDB 记录的参考结果不会被覆盖
+// EN: saveGolden writes the current MonoLite result as a golden file; references recorded from MongoDB are never overwritten.
+func saveGolden(dir string, result TestResult) (bool, error) {
+	path := reference.Path(dir, result.TestName)
+	if existing, err := reference.Read(path); err == nil && existing.Source == reference.SourceMongoDB {
+		return false, nil
+	}
+
+	golden := referenceResult(result)
+	data, err := bson.MarshalExtJSONIndent(golden, true, false, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("Extended JSON 序列化失败: %w", err) // EN: Extended JSON serialization failed
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, data, 0644)
+}
+
+// referenceResult 将运行结果转换为黄金文件格式，事务操作或场景步骤的结果一并转换
+// EN: referenceResult converts a run result into the golden file format, including the results of the transaction operations or scenario steps.
+func referenceResult(result TestResult) ExpectedResult {
+	golden := ExpectedResult{
+		TestName:        result.TestName,
+		Source:          reference.SourceGolden,
+		Documents:       result.RawDocuments,
+		Count:           result.Count,
+		MatchedCount:    result.MatchedCount,
+		ModifiedCount:   result.ModifiedCount,
+		DeletedCount:    result.DeletedCount,
+		UpsertedID:      result.UpsertedID,
+		IndexName:       result.IndexName,
+		VerifyDocuments: result.RawVerifyDocuments,
+		Error:           result.ActionError,
+		ErrorCode:       result.ErrorCode,
+		ErrorCodeName:   result.ErrorCodeName,
+	}
+	for _, op := range result.Operations {
+		golden.Operations = append(golden.Operations, referenceResult(op))
+	}
+	return golden
+}
+
+// checkReference 将结果与参考结果比较，返回所有差异；服务端生成的 ObjectId 在两次运行之间必然不同，比较前统一替换
+// EN: checkReference compares the result with the reference result and returns all differences;
+// EN: ObjectIds generated by the server always differ between runs, so they are normalized before comparing.
+func checkReference(tc TestCase, result *TestResult, actionErr error) []AssertionFailure {
+	fixed := fixtureObjectIDs(tc)
+	failures := compareReference("reference.", tc, tc.Reference, result, actionErr, tc.Action.Method, fixed)
+
+	// 记录了操作结果时逐个比较，旧的参考结果没有这一项
+	// EN: Operation results are compared one by one when recorded; older references do not carry them
+	ops := tc.Reference.Operations
+	if ops == nil {
+		return failures
+	}
+	if len(ops) != len(result.Operations) {
+		failures = append(failures, newFailure("reference.operations.length", int64(len(ops)), int64(len(result.Operations))))
+	}
+	methods := operationMethods(tc)
+	for i := 0; i < len(ops) && i < len(result.Operations) && i < len(methods); i++ {
+		var opErr error
+		if result.Operations[i].ActionError != "" {
+			opErr = errors.New(result.Operations[i].ActionError)
+		}
+		prefix := fmt.Sprintf("reference.operations[%d].", i)
+		failures = append(failures, compareReference(prefix, tc, &ops[i], &result.Operations[i], opErr, methods[i], fixed)...)
+	}
+	return failures
+}
+
+// compareReference 比较单个动作的结果与参考结果，失败的字段路径以 prefix 开头
+// EN: compareReference compares the result of a single action with its reference; failing field paths start with prefix.
+func compareReference(prefix string, tc TestCase, ref *ExpectedResult, result *TestResult, actionErr error, method string, fixed map[primitive.ObjectID]bool) []AssertionFailure {
+	// 不同引擎的错误信息不同，只比较是否出错；双方都有错误码时比较错误码
+	// EN: Error messages differ between engines, so only the presence of an error is compared, plus the codes when both sides carry one
+	if ref.Error != "" || actionErr != nil {
+		if ref.Error != "" && actionErr == nil {
+			return []AssertionFailure{newFailure(prefix+"error", ref.Error, nil)}
+		}
+		if ref.Error == "" && actionErr != nil {
+			return []AssertionFailure{newFailure(prefix+"error", nil, actionErr.Error())}
+		}
+		if ref.ErrorCode != 0 && result.ErrorCode != 0 && ref.ErrorCode != result.ErrorCode {
+			return []AssertionFailure{newFailure(prefix+"error_code", ref.ErrorCode, result.ErrorCode)}
+		}
+		return nil
+	}
+
+	var failures []AssertionFailure
+	failures = appendCountFailure(failures, prefix+"count", &ref.Count, result.Count)
+	failures = appendCountFailure(failures, prefix+"matched_count", &ref.MatchedCount, result.MatchedCount)
+	failures = appendCountFailure(failures, prefix+"modified_count", &ref.ModifiedCount, result.ModifiedCount)
+	failures = appendCountFailure(failures, prefix+"deleted_count", &ref.DeletedCount, result.DeletedCount)
+
+	comparator := newComparator(tc.Comparison)
+	if ref.UpsertedID != nil {
+		failures = append(failures, comparator.compareValue(prefix+"upserted_id",
+			normalizeGeneratedIDs(ref.UpsertedID, fixed), normalizeGeneratedIDs(result.UpsertedID, fixed))...)
+	}
+	if ref.IndexName != "" && ref.IndexName != result.IndexName {
+		failures = append(failures, newFailure(prefix+"index_name", ref.IndexName, result.IndexName))
+	}
+
+	// 只有返回文档的动作才比较文档 // EN: Documents are only compared for actions that return documents
+	if documentMethods[method] {
+		ordered := tc.Comparison != nil && tc.Comparison.Ordered
+		failures = append(failures, comparator.compareResultSet(prefix+"documents",
+			normalizedExpected(ref.Documents, fixed), normalizeDocIDs(result.RawDocuments, fixed), ordered)...)
+	}
+	return failures
+}
+
+// operationMethods 返回每个事务操作或场景步骤的动作方法
+// EN: operationMethods returns the action method of every transaction operation or scenario step.
+func operationMethods(tc TestCase) []string {
+	var methods []string
+	if tc.Transaction != nil {
+		for _, op := range tc.Transaction.Operations {
+			methods = append(methods, op.Action.Method)
+		}
+	}
+	for _, step := range tc.Steps {
+		methods = append(methods, step.Action.Method)
+	}
+	return methods
+}
+
+// checkReferenceVerify 将状态校验查询返回的文档与参考结果中记录的校验文档比较
+// EN: checkReferenceVerify compares the documents returned by the verification query with the ones recorded in the reference result.
+func checkReferenceVerify(tc TestCase, docs []bson.D) []AssertionFailure {
+	fixed := fixtureObjectIDs(tc)
+	ordered := tc.Comparison != nil && tc.Comparison.Ordered
+	return newComparator(tc.Comparison).compareResultSet("reference.verify_documents",
+		normalizedExpected(tc.Reference.VerifyDocuments, fixed), normalizeDocIDs(docs, fixed), ordered)
+}
+
+// documentMethods 返回文档的动作方法，只有这些方法的参考结果会比较 documents
+// EN: documentMethods lists the action methods that return documents; only their reference results compare documents.
+var documentMethods = methodSet(
+	"find", "findOne", "aggregate", "findAndModify",
+	"findOneAndUpdate", "findOneAndReplace", "findOneAndDelete", "listCollections",
+)
+
+// generatedObjectID 替换服务端生成的 ObjectId 的占位值
+// EN: generatedObjectID is the placeholder that replaces ObjectIds generated by the server.
+var generatedObjectID = primitive.NilObjectID
+
+// fixtureObjectIDs 收集测试用例中写明的 ObjectId；其余 ObjectId 都视为服务端生成
+// EN: fixtureObjectIDs collects the ObjectIds spelled out in the test case; every other ObjectId is treated as generated by the server.
+func fixtureObjectIDs(tc TestCase) map[primitive.ObjectID]bool {
+	fixed := make(map[primitive.ObjectID]bool)
+	data, err := bson.Marshal(tc)
+	if err != nil {
+		return fixed
+	}
+	var d bson.D
+	if err := bson.Unmarshal(data, &d); err != nil {
+		return fixed
+	}
+	collectObjectIDs(d, fixed)
+	return fixed
+}
+
+// collectObjectIDs 递归收集值中的所有 ObjectId
+// EN: collectObjectIDs recursively collects every ObjectId in a value.
+func collectObjectIDs(v any, into map[primitive.ObjectID]bool) {
+	switch val := v.(type) {
+	case primitive.ObjectID:
+		into[val] = true
+	case bson.D:
+		for _, e := range val {
+			collectObjectIDs(e.Value, into)
+		}
+	case bson.A:
+		for _, item := range val {
+			collectObjectIDs(item, into)
+		}
+	}
+}
+
+// normalizeGeneratedIDs 将不在 fixed 中的 ObjectId 替换为 generatedObjectID，原值不会被修改
+// EN: normalizeGeneratedIDs replaces every ObjectId missing from fixed with generatedObjectID; the original value is left untouched.
+func normalizeGeneratedIDs(v any, fixed map[primitive.ObjectID]bool) any {
+	switch val := v.(type) {
+	case primitive.ObjectID:
+		if fixed[val] {
+			return val
+		}
+		return generatedObjectID
+	case bson.D:
+		out := make(bson.D, len(val))
+		for i, e := range val {
+			out[i] = bson.E{Key: e.Key, Value: normalizeGeneratedIDs(e.Value, fixed)}
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(val))
+		for i, item := range val {
+			out[i] = normalizeGeneratedIDs(item, fixed)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// normalizeDocIDs 对每个文档执行 normalizeGeneratedIDs
+// EN: normalizeDocIDs applies normalizeGeneratedIDs to every document.
+func normalizeDocIDs(docs []bson.D, fixed map[primitive.ObjectID]bool) []bson.D {
+	out := make([]bson.D, len(docs))
+	for i, d := range docs {
+		out[i] = normalizeGeneratedIDs(d, fixed).(bson.D)
+	}
+	return out
+}
+
+// normalizedExpected 将参考文档规范化后转换为 compareResultSet 所需的预期值列表
+// EN: normalizedExpected normalizes the reference documents and converts them into the expected values compareResultSet takes.
+func normalizedExpected(docs []bson.D, fixed map[primitive.ObjectID]bool) []any {
+	return docsToAny(normalizeDocIDs(docs, fixed))
+}
@@ -0,0 +1,85 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCheckReferenceOperations(t *testing.T) {
+	doc := bson.D{{Key: "_id", Value: "a"}, {Key: "v", Value: int32(1)}}
+	tc := TestCase{Transaction: &Transaction{Operations: []TxnOperation{
+		{Action: TestAction{Method: "insertOne"}},
+		{Action: TestAction{Method: "find"}},
+		{Action: TestAction{Method: "updateOne"}},
+	}}}
+	ref := &ExpectedResult{Operations: []ExpectedResult{
+		{},
+		{Documents: []bson.D{doc}},
+		{Error: "write conflict", ErrorCode: 112},
+	}}
+
+	tests := []struct {
+		name   string
+		ops    []TestResult
+		fields []string
+	}{
+		{
+			name: "matching operations",
+			ops:  []TestResult{{}, {RawDocuments: []bson.D{doc}}, {ActionError: "conflict", ErrorCode: 112}},
+		},
+		{
+			name:   "documents differ",
+			ops:    []TestResult{{}, {}, {ActionError: "conflict", ErrorCode: 112}},
+			fields: []string{"reference.operations[1].documents.length"},
+		},
+		{
+			name:   "missing error",
+			ops:    []TestResult{{}, {RawDocuments: []bson.D{doc}}, {MatchedCount: 1}},
+			fields: []string{"reference.operations[2].error"},
+		},
+		{
+			name:   "different error code",
+			ops:    []TestResult{{}, {RawDocuments: []bson.D{doc}}, {ActionError: "conflict", ErrorCode: 11000}},
+			fields: []string{"reference.operations[2].error_code"},
+		},
+		{
+			name:   "missing operation",
+			ops:    []TestResult{{}, {RawDocuments: []bson.D{doc}}},
+			fields: []string{"reference.operations.length"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := tc
+			tc.Reference = ref
+			failures := checkReference(tc, &TestResult{Operations: tt.ops}, nil)
+			var fields []string
+			for _, f := range failures {
+				fields = append(fields, f.Field)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("failures = %v, want fields %v", fields, tt.fields)
+			}
+			for i := range fields {
+				if fields[i] != tt.fields[i] {
+					t.Errorf("failure %d field = %q, want %q", i, fields[i], tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckReferenceTopLevelError(t *testing.T) {
+	tc := TestCase{Action: TestAction{Method: "insertOne"}, Reference: &ExpectedResult{Error: "duplicate key", ErrorCode: 11000}}
+	if failures := checkReference(tc, &TestResult{ErrorCode: 11000}, errors.New("dup")); len(failures) != 0 {
+		t.Errorf("failures = %v, want none", failures)
+	}
+	if failures := checkReference(tc, &TestResult{}, nil); len(failures) != 1 || failures[0].Field != "reference.error" {
+		t.Errorf("failures = %v, want reference.error", failures)
+	}
+}
@@ -0,0 +1,173 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runner 测试运行器接口；并行运行时 RunTest 会被多个协程同时调用
+// EN: Runner is the interface implemented by test runners; RunTest is called from several goroutines when running in parallel.
+type Runner interface {
+	RunTest(tc TestCase) TestResult // 运行单个测试 // EN: Run a single test case
+	Close() error                   // 释放资源 // EN: Release resources
+}
+
+// runnerFactory 基于数据库文件创建运行器
+// EN: runnerFactory creates a runner on top of a database file.
+type runnerFactory func(dbPath string) (Runner, error)
+
+// IsolatedRunner 每个测试用例都在生成的数据库文件的全新副本上运行
+// EN: IsolatedRunner runs every test case against a fresh copy of the generated database file.
+type IsolatedRunner struct {
+	sourcePath string        // 原始数据库文件 // EN: Source database file
+	tempDir    string        // 副本所在的临时目录 // EN: Temporary directory holding the copies
+	mode       string        // 模式 // EN: Mode
+	factory    runnerFactory // 运行器工厂 // EN: Runner factory
+
+	mu      sync.Mutex // 保护 pending // EN: Guards pending
+	pending int        // 超时后仍在使用副本的测试数 // EN: Number of timed-out tests still using their copy
+}
+
+// NewIsolatedRunner 创建隔离运行器
+// EN: NewIsolatedRunner creates an isolated runner.
+func NewIsolatedRunner(sourcePath, mode string, factory runnerFactory) (*IsolatedRunner, error) {
+	if _, err := os.Stat(sourcePath); err != nil {
+		return nil, fmt.Errorf("数据库文件不可用: %w", err) // EN: Database file unavailable
+	}
+	tempDir, err := os.MkdirTemp("", "monolite-isolated-")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err) // EN: Failed to create temporary directory
+	}
+	return &IsolatedRunner{
+		sourcePath: sourcePath,
+		tempDir:    tempDir,
+		mode:       mode,
+		factory:    factory,
+	}, nil
+}
+
+// Close 删除所有数据库副本；仍有超时的测试在使用副本时保留临时目录
+// EN: Close removes all database copies; the temporary directory is kept while timed-out tests are still using their copies.
+func (r *IsolatedRunner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending > 0 {
+		log.Printf("警告: %d 个超时的测试仍在运行，保留临时目录 %s", r.pending, r.tempDir) // EN: Warning: timed-out tests still running, temporary directory kept
+		return nil
+	}
+	return os.RemoveAll(r.tempDir)
+}
+
+// RunTest 在全新的数据库副本上运行单个测试
+// EN: RunTest runs a single test case against a fresh database copy.
+func (r *IsolatedRunner) RunTest(tc TestCase) TestResult {
+	start := time.Now()
+
+	caseDir, err := os.MkdirTemp(r.tempDir, "case-")
+	if err != nil {
+		return r.failedResult(tc, start, err)
+	}
+
+	dbPath := filepath.Join(caseDir, filepath.Base(r.sourcePath))
+	if err := copyDatabase(r.sourcePath, dbPath); err != nil {
+		os.RemoveAll(caseDir)
+		return r.failedResult(tc, start, err)
+	}
+
+	runner, err := r.factory(dbPath)
+	if err != nil {
+		os.RemoveAll(caseDir)
+		return r.failedResult(tc, start, err)
+	}
+
+	result := runner.RunTest(tc)
+	r.release(runner, caseDir)
+	return result
+}
+
+// release 关闭运行器并删除副本；超时的测试仍在使用副本时，推迟到其协程结束后再释放
+// EN: release closes the runner and removes the copy; while a timed-out test is still using the copy, this is deferred until its goroutine finishes.
+func (r *IsolatedRunner) release(runner Runner, caseDir string) {
+	var abandoned <-chan struct{}
+	if a, ok := runner.(abandoner); ok {
+		abandoned = a.Abandoned()
+	}
+	if abandoned == nil {
+		runner.Close()
+		os.RemoveAll(caseDir)
+		return
+	}
+
+	r.mu.Lock()
+	r.pending++
+	r.mu.Unlock()
+	go func() {
+		<-abandoned
+		runner.Close()
+		os.RemoveAll(caseDir)
+		r.mu.Lock()
+		r.pending--
+		r.mu.Unlock()
+	}()
+}
+
+// failedResult 构造隔离环境准备失败的结果
+// EN: failedResult builds the result for a failure while preparing the isolated environment.
+func (r *IsolatedRunner) failedResult(tc TestCase, start time.Time, err error) TestResult {
+	msg := fmt.Sprintf("隔离环境准备失败: %v", err) // EN: Failed to prepare isolated environment
+	return TestResult{
+		TestName:    tc.Name,
+		Language:    "go",
+		Mode:        r.mode,
+		Error:       msg,
+		ActionError: msg,
+		Duration:    time.Since(start).Milliseconds(),
+	}
+}
+
+// copyDatabase 复制数据库文件及其附属文件（如 .wal）
+// EN: copyDatabase copies the database file together with its sidecar files such as .wal.
+func copyDatabase(src, dst string) error {
+	matches, err := filepath.Glob(src + "*")
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		suffix := strings.TrimPrefix(path, src)
+		if suffix != "" && !strings.HasPrefix(suffix, ".") {
+			continue
+		}
+		if err := copyFile(path, dst+suffix); err != nil {
+			return fmt.Errorf("复制 %s 失败: %w", path, err) // EN: Failed to copy
+		}
+	}
+	return nil
+}
+
+// copyFile 复制单个文件
+// EN: copyFile copies a single file.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
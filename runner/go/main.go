@@ -8,15 +8,31 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 // 命令行参数 // EN: Command line arguments
 var (
-	mode       = flag.String("mode", "api", "测试模式: api 或 wire")                             // EN: Test mode: api or wire
+	mode       = flag.String("mode", "api", "测试模式: api、wire 或 diff")                      // EN: Test mode: api, wire or diff
 	monoDBPath = flag.String("monodb", "../../testdata/fixtures/test.monodb", "MonoLite 数据库文件") // EN: MonoLite database file
 	testCases  = flag.String("testcases", "../../testdata/fixtures/testcases.json", "测试用例文件")   // EN: Test cases file
 	output     = flag.String("output", "../../reports/go_results.json", "结果输出文件")              // EN: Result output file
-	wirePort   = flag.Int("port", 27018, "Wire Protocol 服务端口")                                 // EN: Wire Protocol server port
+	wirePort   = flag.Int("port", 0, "Wire Protocol 服务端口（0 表示自动选择空闲端口）")             // EN: Wire Protocol server port (0 picks a free port)
+
+	expectedDir  = flag.String("expected-dir", "../../testdata/fixtures/expected", "参考结果目录（MongoDB 记录或黄金文件）") // EN: Reference results directory (MongoDB recordings or golden files)
+	updateGolden = flag.Bool("update-golden", false, "将当前 MonoLite 结果写入黄金文件")                                     // EN: Write current MonoLite results as golden files
+	isolate      = flag.Bool("isolate", false, "每个测试用例使用全新的数据库副本")                                           // EN: Run each test case against a fresh database copy
+	parallel     = flag.Int("parallel", 1, "并发运行测试的工作协程数")                                                       // EN: Number of workers running tests concurrently
+	timeout      = flag.Duration("timeout", 30*time.Second, "单个测试的超时时间（0 表示不限制）")                            // EN: Timeout of a single test (0 means no limit)
+
+	runPattern  = flag.String("run", "", "只运行名称匹配该正则的测试")         // EN: Only run tests whose name matches this regex
+	skipPattern = flag.String("skip", "", "跳过名称匹配该正则的测试")         // EN: Skip tests whose name matches this regex
+	categories  = flag.String("category", "", "只运行指定分类（逗号分隔）")   // EN: Only run the given categories (comma separated)
+	methods     = flag.String("method", "", "只运行指定动作方法（逗号分隔）") // EN: Only run the given action methods (comma separated)
 )
 
 // main 主函数
@@ -33,14 +49,49 @@ func main() {
 	}
 	log.Printf("加载了 %d 个测试用例", len(suite.Tests)) // EN: Loaded %d test cases
 
+	// 差异模式比较两种运行器的结果，不产生黄金文件 // EN: Diff mode compares the two runners with each other and produces no golden files
+	if *mode == "diff" && *updateGolden {
+		log.Fatalf("差异模式不支持 --update-golden") // EN: Diff mode does not support --update-golden
+	}
+
+	// 黄金文件记录的结果不能依赖执行顺序，写入时总是使用隔离模式
+	// EN: Golden results must not depend on execution order, so they are always written in isolation mode
+	if *updateGolden && !*isolate {
+		log.Printf("写入黄金文件: 自动启用 --isolate") // EN: Writing golden files: enabling --isolate automatically
+		*isolate = true
+	}
+
+	// 加载参考结果（更新黄金文件时不比较）// EN: Load reference results (not compared when updating golden files)
+	if !*updateGolden {
+		n, err := loadReferences(*expectedDir, suite)
+		if err != nil {
+			log.Fatalf("加载参考结果失败: %v", err) // EN: Failed to load reference results
+		}
+		log.Printf("加载了 %d 个参考结果", n) // EN: Loaded %d reference results
+		if !*isolate && hasGoldenReferences(suite) {
+			log.Printf("警告: 黄金文件在隔离模式下记录，未启用 --isolate 时结果可能依赖执行顺序") // EN: Warning: golden files are recorded in isolation mode; without --isolate results may depend on execution order
+		}
+	}
+
+	// 构造测试筛选条件 // EN: Build the test filter
+	filter, err := newTestFilter(*runPattern, *skipPattern, *categories, *methods)
+	if err != nil {
+		log.Fatalf("筛选条件无效: %v", err) // EN: Invalid filter
+	}
+
+	// 列出当前运行器无法执行的方法 // EN: List the methods the current runner cannot execute
+	reportUnsupportedMethods(suite, *mode)
+
 	var results []TestResult
-	var passed, failed int
+	var summary Summary
 
 	switch *mode {
 	case "api":
-		results, passed, failed = runAPITests(suite)
+		results, summary = runAPITests(suite, filter)
 	case "wire":
-		results, passed, failed = runWireTests(suite)
+		results, summary = runWireTests(suite, filter)
+	case "diff":
+		results, summary = runDiffTests(suite, filter)
 	default:
 		log.Fatalf("未知模式: %s", *mode) // EN: Unknown mode
 	}
@@ -50,92 +101,281 @@ func main() {
 		Language: "go",
 		Mode:     *mode,
 		Results:  results,
-		Summary: Summary{
-			Total:  len(results),
-			Passed: passed,
-			Failed: failed,
-		},
+		Summary:  summary,
 	}
 
 	if err := saveResults(*output, resultsFile); err != nil {
 		log.Fatalf("保存结果失败: %v", err) // EN: Failed to save results
 	}
 
-	log.Printf("=== 测试完成 ===")                                     // EN: Test completed
-	log.Printf("通过: %d, 失败: %d, 总计: %d", passed, failed, len(results)) // EN: Passed: %d, Failed: %d, Total: %d
-	log.Printf("结果已保存到: %s", *output)                                // EN: Results saved to
+	// 写入黄金文件 // EN: Write golden files
+	if *updateGolden {
+		if err := writeGoldens(*expectedDir, results); err != nil {
+			log.Fatalf("写入黄金文件失败: %v", err) // EN: Failed to write golden files
+		}
+	}
+
+	log.Printf("=== 测试完成 ===")                                                                                       // EN: Test completed
+	log.Printf("通过: %d, 失败: %d, 跳过: %d, 总计: %d", summary.Passed, summary.Failed, summary.Skipped, summary.Total) // EN: Passed: %d, Failed: %d, Skipped: %d, Total: %d
+	log.Printf("结果已保存到: %s", *output)                                                                              // EN: Results saved to
 }
 
-// loadTestCases 加载测试用例
-// EN: loadTestCases loads test cases from a JSON file.
+// loadTestCases 加载 Extended JSON v2 格式的测试用例，保留 BSON 类型和字段顺序
+// EN: loadTestCases loads test cases in Extended JSON v2, preserving BSON types and field order.
 func loadTestCases(path string) (*TestSuite, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 	var suite TestSuite
-	if err := json.Unmarshal(data, &suite); err != nil {
+	if err := bson.UnmarshalExtJSON(data, false, &suite); err != nil {
 		return nil, err
 	}
 	return &suite, nil
 }
 
+// methodSet 辅助函数：将方法名列表转换为集合
+// EN: methodSet is a helper function that converts a list of method names into a set.
+func methodSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// reportUnsupportedMethods 启动时检查测试用例用到的方法（包括场景步骤和事务操作），
+// 列出当前模式的运行器无法执行的方法及受影响的测试
+// EN: reportUnsupportedMethods checks the methods used by the test cases (including scenario steps and transaction operations) at startup,
+// EN: and lists the methods the runner of the current mode cannot execute together with the affected tests.
+func reportUnsupportedMethods(suite *TestSuite, mode string) {
+	if mode == "diff" {
+		// 差异模式同时使用两种运行器 // EN: Diff mode uses both runners
+		reportUnsupportedMethods(suite, "api")
+		reportUnsupportedMethods(suite, "wire")
+		return
+	}
+	actions, txnActions := apiMethods, apiTxnMethods
+	if mode == "wire" {
+		actions, txnActions = wireMethods, wireMethods
+	}
+
+	affected := make(map[string][]string)
+	for _, tc := range suite.Tests {
+		for _, method := range unsupportedMethods(tc, actions, txnActions) {
+			affected[method] = append(affected[method], tc.Name)
+		}
+	}
+	if len(affected) == 0 {
+		return
+	}
+
+	missing := make([]string, 0, len(affected))
+	for method := range affected {
+		missing = append(missing, method)
+	}
+	sort.Strings(missing)
+	log.Printf("警告: %s 运行器无法执行 %d 个方法: %s", mode, len(missing), strings.Join(missing, ", ")) // EN: Warning: the %s runner cannot execute %d methods
+	for _, method := range missing {
+		log.Printf("  %s: %s", method, strings.Join(affected[method], ", "))
+	}
+}
+
+// unsupportedMethods 返回测试用到但运行器无法执行的方法，每个方法只出现一次
+// EN: unsupportedMethods returns the methods a test uses that the runner cannot execute, each listed once.
+func unsupportedMethods(tc TestCase, actions, txnActions map[string]bool) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	check := func(method string, supported map[string]bool) {
+		if !supported[method] && !seen[method] {
+			seen[method] = true
+			missing = append(missing, method)
+		}
+	}
+
+	check(tc.Action.Method, actions)
+	for _, step := range tc.Steps {
+		check(step.Action.Method, actions)
+	}
+	if tc.Transaction != nil {
+		for _, op := range tc.Transaction.Operations {
+			if op.Session == SessionNone {
+				check(op.Action.Method, actions)
+			} else {
+				check(op.Action.Method, txnActions)
+			}
+		}
+	}
+	return missing
+}
+
 // runAPITests 运行 API 模式测试
 // EN: runAPITests runs tests in API mode.
-func runAPITests(suite *TestSuite) ([]TestResult, int, int) {
-	runner, err := NewAPIRunner(*monoDBPath)
+func runAPITests(suite *TestSuite, filter *testFilter) ([]TestResult, Summary) {
+	runner, err := openRunner("api", newAPIRunner)
 	if err != nil {
 		log.Fatalf("创建 API 运行器失败: %v", err) // EN: Failed to create API runner
 	}
 	defer runner.Close()
 
-	var results []TestResult
-	passed, failed := 0, 0
+	return runSuite(suite, runner, filter)
+}
+
+// runWireTests 运行 Wire 模式测试
+// EN: runWireTests runs tests in Wire protocol mode.
+func runWireTests(suite *TestSuite, filter *testFilter) ([]TestResult, Summary) {
+	runner, err := openRunner("wire", newWireRunner)
+	if err != nil {
+		log.Fatalf("创建 Wire 运行器失败: %v", err) // EN: Failed to create Wire runner
+	}
+	defer runner.Close()
+
+	return runSuite(suite, runner, filter)
+}
+
+// runDiffTests 运行差异模式测试：每个测试分别在 API 和 Wire 模式下运行并比较结果
+// EN: runDiffTests runs tests in diff mode: every test runs in both API and wire mode and the results are compared.
+func runDiffTests(suite *TestSuite, filter *testFilter) ([]TestResult, Summary) {
+	runner, err := NewDiffRunner(*monoDBPath, suiteWorkers(), *isolate)
+	if err != nil {
+		log.Fatalf("创建差异模式运行器失败: %v", err) // EN: Failed to create diff mode runner
+	}
+	defer runner.Close()
+
+	if *isolate {
+		log.Printf("差异模式: 每个测试在 %s 的两份全新副本上运行，expected 为 API 结果，actual 为 Wire 结果", *monoDBPath) // EN: Diff mode: each test runs on two fresh copies of %s, expected is the API result and actual the wire result
+	} else {
+		log.Printf("差异模式: 每个工作协程在 %s 的两份副本上运行测试，expected 为 API 结果，actual 为 Wire 结果", *monoDBPath) // EN: Diff mode: each worker runs tests on two copies of %s, expected is the API result and actual the wire result
+	}
+	return runSuite(suite, runner, filter)
+}
 
+// suiteWorkers 返回实际使用的工作协程数；隔离模式和差异模式下每个工作协程都启动自己的 Wire 服务器，使用固定端口时只能顺序运行
+// EN: suiteWorkers returns the number of workers actually used; in isolated and diff mode every worker starts its own wire server,
+// EN: so a fixed port forces sequential runs.
+func suiteWorkers() int {
+	if *parallel > 1 && (*isolate || *mode == "diff") && *mode != "api" && *wirePort != 0 {
+		return 1
+	}
+	return *parallel
+}
+
+// runSuite 运行所有未被筛除的测试；结果顺序与测试用例顺序一致
+// EN: runSuite runs every test that is not filtered out; results keep the order of the test cases.
+func runSuite(suite *TestSuite, runner Runner, filter *testFilter) ([]TestResult, Summary) {
+	// 差异模式中每个测试独占一对运行器，先前测试的影响在两份副本上相同，无需按集合分组
+	// EN: In diff mode every test has a runner pair to itself and earlier tests affect both copies alike, so no grouping by collection is needed
+	isolated := *isolate || *mode == "diff"
+	workers := suiteWorkers()
+	if workers < *parallel {
+		log.Printf("警告: 多个 Wire 服务器无法共用固定端口 %d，改为顺序运行", *wirePort) // EN: Warning: several wire servers cannot share fixed port %d, running sequentially
+	}
+	if workers > 1 {
+		log.Printf("并行模式: %d 个工作协程", workers) // EN: Parallel mode: %d workers
+	}
+
+	// 被筛除的测试直接记为跳过 // EN: Filtered-out tests are recorded as skipped directly
+	results := make([]TestResult, len(suite.Tests))
+	var selected []int
+	var tests []TestCase
 	for i, tc := range suite.Tests {
-		log.Printf("[%d/%d] 测试: %s", i+1, len(suite.Tests), tc.Name) // EN: [%d/%d] Test: %s
-		result := runner.RunTest(tc)
-		results = append(results, result)
+		if reason := filter.skipReason(tc); reason != "" {
+			results[i] = skippedResult(tc, *mode, reason)
+			continue
+		}
+		selected = append(selected, i)
+		tests = append(tests, tc)
+	}
+	if len(selected) < len(suite.Tests) {
+		log.Printf("筛选后运行 %d 个测试，跳过 %d 个", len(selected), len(suite.Tests)-len(selected)) // EN: Running %d tests after filtering, skipping %d
+	}
 
-		if result.Success {
-			passed++
-			log.Printf("  ✓ 通过 (%dms)", result.Duration) // EN: Passed
-		} else {
-			failed++
-			log.Printf("  ✗ 失败: %s (%dms)", result.Error, result.Duration) // EN: Failed
+	for j, result := range runParallel(tests, runner, workers, isolated) {
+		results[selected[j]] = result
+	}
+
+	summary := Summary{Total: len(results)}
+	for _, result := range results {
+		switch result.Status {
+		case StatusPassed:
+			summary.Passed++
+		case StatusSkipped:
+			summary.Skipped++
+		case StatusTimeout:
+			summary.Failed++
+			summary.TimedOut++
+		default:
+			summary.Failed++
 		}
 	}
+	return results, summary
+}
 
-	return results, passed, failed
+// logResult 输出单个测试结果
+// EN: logResult logs the result of a single test.
+func logResult(done, total int, result TestResult) {
+	log.Printf("[%d/%d] 测试: %s", done, total, result.TestName) // EN: [%d/%d] Test: %s
+	if result.Success {
+		log.Printf("  ✓ 通过 (%dms)", result.Duration) // EN: Passed
+	} else {
+		log.Printf("  ✗ 失败: %s (%dms)", result.Error, result.Duration) // EN: Failed
+	}
 }
 
-// runWireTests 运行 Wire 模式测试
-// EN: runWireTests runs tests in Wire protocol mode.
-func runWireTests(suite *TestSuite) ([]TestResult, int, int) {
-	runner, err := NewWireRunner(*monoDBPath, *wirePort)
+// openRunner 创建运行器；开启隔离时每个测试使用全新的数据库副本
+// EN: openRunner creates a runner; with isolation enabled every test uses a fresh database copy.
+func openRunner(mode string, factory runnerFactory) (Runner, error) {
+	if !*isolate {
+		return factory(*monoDBPath)
+	}
+	runner, err := NewIsolatedRunner(*monoDBPath, mode, factory)
 	if err != nil {
-		log.Fatalf("创建 Wire 运行器失败: %v", err) // EN: Failed to create Wire runner
+		return nil, err
 	}
-	defer runner.Close()
+	log.Printf("隔离模式: 每个测试使用 %s 的全新副本", *monoDBPath) // EN: Isolation mode: each test uses a fresh copy of %s
+	return runner, nil
+}
 
-	var results []TestResult
-	passed, failed := 0, 0
+// newAPIRunner 创建 API 运行器
+// EN: newAPIRunner creates an API runner.
+func newAPIRunner(dbPath string) (Runner, error) {
+	runner, err := NewAPIRunner(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
 
-	for i, tc := range suite.Tests {
-		log.Printf("[%d/%d] 测试: %s", i+1, len(suite.Tests), tc.Name) // EN: [%d/%d] Test: %s
-		result := runner.RunTest(tc)
-		results = append(results, result)
+// newWireRunner 创建 Wire 运行器
+// EN: newWireRunner creates a Wire runner.
+func newWireRunner(dbPath string) (Runner, error) {
+	runner, err := NewWireRunner(dbPath, *wirePort)
+	if err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
 
-		if result.Success {
-			passed++
-			log.Printf("  ✓ 通过 (%dms)", result.Duration) // EN: Passed
+// writeGoldens 将所有结果写入黄金文件
+// EN: writeGoldens writes all results as golden files.
+func writeGoldens(dir string, results []TestResult) error {
+	written, kept := 0, 0
+	for _, result := range results {
+		if result.Status == StatusSkipped || result.Status == StatusTimeout {
+			continue
+		}
+		ok, err := saveGolden(dir, result)
+		if err != nil {
+			return err
+		}
+		if ok {
+			written++
 		} else {
-			failed++
-			log.Printf("  ✗ 失败: %s (%dms)", result.Error, result.Duration) // EN: Failed
+			kept++
 		}
 	}
-
-	return results, passed, failed
+	log.Printf("黄金文件: 写入 %d 个, 保留 %d 个 MongoDB 参考结果 (%s)", written, kept, dir) // EN: Golden files: wrote %d, kept %d MongoDB references
+	return nil
 }
 
 // saveResults 保存测试结果
@@ -0,0 +1,170 @@
+// Created by Yanjunhui
+
+package main
+
+import "sync"
+
+// runParallel 使用工作池运行测试，结果按测试用例顺序返回
+// 同一组内的测试按原顺序依次运行，不同组之间并发运行；
+// 数据库级的测试在所有组结束后单独依次运行
+// EN: runParallel runs tests with a worker pool and returns results in test case order.
+// EN: Tests in the same group run sequentially in their original order; different groups run concurrently;
+// EN: database-scoped tests run on their own, one after the other, once every group has finished.
+func runParallel(tests []TestCase, runner Runner, workers int, isolated bool) []TestResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]TestResult, len(tests))
+	groups, exclusive := independentGroups(tests, workers, isolated)
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	run := func(i int) {
+		result := runner.RunTest(tests[i])
+		result.Status = resultStatus(result)
+		results[i] = result
+
+		mu.Lock()
+		done++
+		logResult(done, len(tests), result)
+		mu.Unlock()
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				for _, i := range group {
+					run(i)
+				}
+			}
+		}()
+	}
+
+	for _, group := range groups {
+		jobs <- group
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, i := range exclusive {
+		run(i)
+	}
+	return results
+}
+
+// databaseMethods 作用于整个数据库而非单个集合的动作，其结果受其他集合的创建和删除影响
+// EN: databaseMethods are actions scoped to the whole database rather than a single collection; their results depend on other collections being created and dropped.
+var databaseMethods = methodSet("listCollections")
+
+// databaseScoped 判断测试是否作用于整个数据库：事务和场景可能跨集合并持有锁，listCollections 会看到所有集合
+// EN: databaseScoped reports whether a test is scoped to the whole database: transactions and scenarios may span collections and hold locks,
+// EN: and listCollections sees every collection.
+func databaseScoped(tc TestCase) bool {
+	return tc.Transaction != nil || tc.Steps != nil || databaseMethods[tc.Action.Method]
+}
+
+// independentGroups 将测试划分为互不影响的组，返回每组的测试下标，以及必须在所有组结束后单独运行的测试下标
+// 隔离模式下每个测试使用独立的数据库副本，各自成组；
+// 否则访问同一集合的测试归为一组，以保持原有的执行顺序，数据库级的测试不参与并发
+// EN: independentGroups splits tests into groups that cannot affect each other and returns the test indexes of each group,
+// EN: together with the indexes of the tests that must run on their own after every group has finished.
+// EN: In isolated mode every test has its own database copy and forms its own group;
+// EN: otherwise tests touching the same collection share a group so their original order is kept, and database-scoped tests are kept out of the concurrent phase.
+func independentGroups(tests []TestCase, workers int, isolated bool) (groups [][]int, exclusive []int) {
+	if workers <= 1 {
+		all := make([]int, len(tests))
+		for i := range tests {
+			all[i] = i
+		}
+		return [][]int{all}, nil
+	}
+
+	if isolated {
+		groups := make([][]int, len(tests))
+		for i := range tests {
+			groups[i] = []int{i}
+		}
+		return groups, nil
+	}
+
+	// 并查集：按集合合并测试 // EN: Union-find: merge tests by collection
+	parent := make([]int, len(tests))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owner := make(map[string]int)
+	for i, tc := range tests {
+		if databaseScoped(tc) {
+			exclusive = append(exclusive, i)
+			continue
+		}
+		for _, name := range testCollections(tc) {
+			if j, ok := owner[name]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				owner[name] = i
+			}
+		}
+	}
+
+	// 按首个测试的顺序输出各组 // EN: Emit groups in the order of their first test
+	index := make(map[int]int)
+	for i, tc := range tests {
+		if databaseScoped(tc) {
+			continue
+		}
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups, exclusive
+}
+
+// testCollections 返回测试访问的所有集合
+// EN: testCollections returns every collection a test touches.
+func testCollections(tc TestCase) []string {
+	names := []string{tc.Collection}
+	if tc.Verify != nil && tc.Verify.Collection != "" {
+		names = append(names, tc.Verify.Collection)
+	}
+	if tc.Transaction != nil {
+		for _, op := range tc.Transaction.Operations {
+			if op.Collection != "" {
+				names = append(names, op.Collection)
+			}
+		}
+	}
+	for _, step := range tc.Steps {
+		if step.Collection != "" {
+			names = append(names, step.Collection)
+		}
+	}
+	for _, steps := range [][]SetupStep{tc.Setup, tc.Teardown} {
+		for _, step := range steps {
+			if step.Collection != "" {
+				names = append(names, step.Collection)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,131 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/monolite/monolite-test/reference"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// runScenario 依次执行场景步骤：先替换对先前步骤结果的引用，再执行动作并检查该步骤的预期结果；
+// 后续步骤可能依赖失败步骤的结果，因此在第一个失败的步骤处停止
+// EN: runScenario executes the scenario steps in order: references to earlier results are substituted first,
+// EN: then the action runs and the expected result of the step is checked;
+// EN: later steps may depend on the result of a failed step, so execution stops at the first failure.
+func runScenario(tc TestCase, mode string, execute func(TestCase, *TestResult) error) []TestResult {
+	var results []TestResult
+	for i, step := range tc.Steps {
+		name := tc.Collection
+		if step.Collection != "" {
+			name = step.Collection
+		}
+		stepResult := TestResult{TestName: stepName(i, step), Language: "go", Mode: mode}
+
+		var failures []AssertionFailure
+		resolved, err := resolveStep(step, results)
+		if err != nil {
+			failures = []AssertionFailure{newFailure(reference.StepRefKey, nil, err.Error())}
+		} else {
+			actionErr := execute(TestCase{Collection: name, Action: resolved.Action}, &stepResult)
+			if actionErr != nil {
+				recordActionError(&stepResult, actionErr)
+			}
+			failures = checkExpected(resolved.Expected, tc.Comparison, &stepResult, actionErr)
+		}
+
+		stepResult.Success = len(failures) == 0
+		if !stepResult.Success {
+			stepResult.AssertionFailures = failures
+			stepResult.Error = summarizeFailures(failures)
+		}
+		results = append(results, stepResult)
+		if !stepResult.Success {
+			break
+		}
+	}
+	return results
+}
+
+// evaluateScenario 记录第一个失败步骤的序号，并将其断言失败并入测试结果
+// EN: evaluateScenario records the index of the first failing step and merges its assertion failures into the test result.
+func evaluateScenario(tc TestCase, result *TestResult) {
+	for i, step := range result.Operations {
+		if step.Success {
+			continue
+		}
+		failed := i
+		result.FailedStep = &failed
+		result.Success = false
+		for _, f := range step.AssertionFailures {
+			f.Field = fmt.Sprintf("steps[%d].%s", i, f.Field)
+			result.AssertionFailures = append(result.AssertionFailures, f)
+		}
+		result.Error = summarizeFailures(result.AssertionFailures)
+		return
+	}
+}
+
+// stepName 返回步骤的显示名称
+// EN: stepName returns the display name of a step.
+func stepName(i int, step ScenarioStep) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("%d:%s", i, step.Action.Method)
+}
+
+// resolveStep 替换步骤动作和预期结果中的 $stepRef 引用，返回遇到的第一个无法解析的引用
+// EN: resolveStep substitutes the $stepRef references in the action and expected result of a step and returns the first unresolvable reference.
+func resolveStep(step ScenarioStep, results []TestResult) (ScenarioStep, error) {
+	outputs := make([]bson.D, len(results))
+	for i, result := range results {
+		outputs[i] = stepOutputs(result)
+	}
+	var firstErr error
+	resolve := func(v any) any {
+		resolved, err := reference.ResolveStepRefs(v, outputs)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return resolved
+	}
+
+	step.Action.Filter = resolve(step.Action.Filter)
+	step.Action.Update = resolve(step.Action.Update)
+	step.Action.Doc = resolve(step.Action.Doc)
+	step.Action.Options = resolve(step.Action.Options)
+	if step.Action.Docs != nil {
+		step.Action.Docs = toSlice(resolve(bson.A(step.Action.Docs)))
+	}
+	if step.Expected.Documents != nil {
+		step.Expected.Documents = toSlice(resolve(bson.A(step.Expected.Documents)))
+	}
+	step.Expected.UpsertedID = resolve(step.Expected.UpsertedID)
+	return step, firstErr
+}
+
+// stepOutputs 返回步骤结果中可被引用的字段
+// EN: stepOutputs returns the fields of a step result that can be referenced.
+func stepOutputs(result TestResult) bson.D {
+	docs := make(bson.A, len(result.RawDocuments))
+	for i, d := range result.RawDocuments {
+		docs[i] = d
+	}
+	out := bson.D{
+		{Key: "inserted_ids", Value: bson.A(result.InsertedIDs)},
+		{Key: "documents", Value: docs},
+		{Key: "count", Value: result.Count},
+		{Key: "matched_count", Value: result.MatchedCount},
+		{Key: "modified_count", Value: result.ModifiedCount},
+		{Key: "deleted_count", Value: result.DeletedCount},
+	}
+	if len(result.InsertedIDs) > 0 {
+		out = append(out, bson.E{Key: "inserted_id", Value: result.InsertedIDs[0]})
+	}
+	if result.UpsertedID != nil {
+		out = append(out, bson.E{Key: "upserted_id", Value: result.UpsertedID})
+	}
+	return out
+}
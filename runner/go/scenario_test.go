@@ -0,0 +1,106 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/monolite/monolite-test/reference"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestResolveStep(t *testing.T) {
+	ref := func(path string) bson.D { return bson.D{{Key: reference.StepRefKey, Value: path}} }
+	results := []TestResult{
+		{InsertedIDs: []any{"a1", "a2"}},
+		{
+			RawDocuments: []bson.D{{{Key: "_id", Value: "a2"}, {Key: "n", Value: int32(3)}}},
+			Count:        1,
+			UpsertedID:   "u1",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		step    ScenarioStep
+		want    ScenarioStep
+		wantErr bool
+	}{
+		{
+			name: "filter and update",
+			step: ScenarioStep{Action: TestAction{
+				Method: "updateOne",
+				Filter: bson.D{{Key: "_id", Value: ref("0.inserted_id")}},
+				Update: bson.D{{Key: "$set", Value: bson.D{{Key: "n", Value: ref("1.documents.0.n")}}}},
+			}},
+			want: ScenarioStep{Action: TestAction{
+				Method: "updateOne",
+				Filter: bson.D{{Key: "_id", Value: "a1"}},
+				Update: bson.D{{Key: "$set", Value: bson.D{{Key: "n", Value: int32(3)}}}},
+			}},
+		},
+		{
+			name: "documents and expected result",
+			step: ScenarioStep{
+				Action:   TestAction{Method: "insertMany", Docs: []any{bson.D{{Key: "parent", Value: ref("0.inserted_ids.1")}}}},
+				Expected: Expected{Documents: []any{bson.D{{Key: "_id", Value: ref("1.upserted_id")}}}, UpsertedID: ref("1.upserted_id")},
+			},
+			want: ScenarioStep{
+				Action:   TestAction{Method: "insertMany", Docs: []any{bson.D{{Key: "parent", Value: "a2"}}}},
+				Expected: Expected{Documents: []any{bson.D{{Key: "_id", Value: "u1"}}}, UpsertedID: "u1"},
+			},
+		},
+		{
+			name: "count",
+			step: ScenarioStep{Action: TestAction{Method: "find", Options: bson.D{{Key: "limit", Value: ref("1.count")}}}},
+			want: ScenarioStep{Action: TestAction{Method: "find", Options: bson.D{{Key: "limit", Value: int64(1)}}}},
+		},
+		{
+			name:    "later step",
+			step:    ScenarioStep{Action: TestAction{Method: "find", Filter: ref("2.inserted_id")}},
+			wantErr: true,
+		},
+		{
+			name:    "upserted_id absent",
+			step:    ScenarioStep{Action: TestAction{Method: "find", Filter: bson.D{{Key: "_id", Value: ref("0.upserted_id")}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveStep(tt.step, results)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveStep = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepOutputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		result TestResult
+		path   string
+		want   any
+		found  bool
+	}{
+		{"first inserted id", TestResult{InsertedIDs: []any{"a", "b"}}, "inserted_id", "a", true},
+		{"inserted ids", TestResult{InsertedIDs: []any{"a", "b"}}, "inserted_ids.1", "b", true},
+		{"no inserted id", TestResult{}, "inserted_id", nil, false},
+		{"upserted id", TestResult{UpsertedID: "u"}, "upserted_id", "u", true},
+		{"no upserted id", TestResult{}, "upserted_id", nil, false},
+		{"counts", TestResult{MatchedCount: 2, ModifiedCount: 1, DeletedCount: 3}, "deleted_count", int64(3), true},
+		{"document field", TestResult{RawDocuments: []bson.D{{{Key: "v", Value: "x"}}}}, "documents.0.v", "x", true},
+	}
+	for _, tt := range tests {
+		got, found := reference.LookupPath(stepOutputs(tt.result), tt.path)
+		if found != tt.found || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %q = %v, %v; want %v, %v", tt.name, tt.path, got, found, tt.want, tt.found)
+		}
+	}
+}
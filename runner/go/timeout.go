@@ -0,0 +1,99 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// goroutineDumpSize 协程堆栈快照的最大字节数
+// EN: goroutineDumpSize is the maximum size of a goroutine dump in bytes.
+const goroutineDumpSize = 1 << 20
+
+// testTimeout 返回测试的超时时间；用例的 timeout_ms 优先于 --timeout，0 表示不限制
+// EN: testTimeout returns the timeout of a test; the case's timeout_ms takes precedence over --timeout, 0 means no limit.
+func testTimeout(tc TestCase) time.Duration {
+	if tc.TimeoutMS > 0 {
+		return time.Duration(tc.TimeoutMS) * time.Millisecond
+	}
+	return *timeout
+}
+
+// runWithWatchdog 在看门狗下运行 fn；超时时立即返回，fn 所在的协程被遗弃，
+// 此时返回的 abandoned 通道在该协程结束时关闭；fn 按时完成时 abandoned 为 nil
+// EN: runWithWatchdog runs fn under a watchdog; on timeout it returns immediately and abandons the goroutine running fn,
+// EN: in which case the returned abandoned channel is closed once that goroutine finishes; abandoned is nil when fn completes in time.
+func runWithWatchdog(limit time.Duration, fn func() TestResult) (result TestResult, abandoned <-chan struct{}) {
+	if limit <= 0 {
+		return fn(), nil
+	}
+
+	done := make(chan TestResult, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		done <- fn()
+	}()
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result, nil
+	case <-timer.C:
+		return TestResult{}, finished
+	}
+}
+
+// abandoner 由可能在超时后留下仍在使用数据库的协程的运行器实现
+// EN: abandoner is implemented by runners that may leave a goroutine still using the database behind after a timeout.
+type abandoner interface {
+	// Abandoned 返回被遗弃协程结束时关闭的通道；没有仍在运行的协程时返回 nil
+	// EN: Abandoned returns a channel closed when the abandoned goroutine finishes, or nil when none is still running.
+	Abandoned() <-chan struct{}
+}
+
+// pending 判断通道是否仍未关闭；nil 通道视为已结束
+// EN: pending reports whether the channel is still open; a nil channel counts as finished.
+func pending(ch <-chan struct{}) bool {
+	if ch == nil {
+		return false
+	}
+	select {
+	case <-ch:
+		return false
+	default:
+		return true
+	}
+}
+
+// contextWithLimit 创建带超时的上下文；limit 为 0 时不设截止时间
+// EN: contextWithLimit creates a context with a timeout; no deadline is set when limit is 0.
+func contextWithLimit(parent context.Context, limit time.Duration) (context.Context, context.CancelFunc) {
+	if limit <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, limit)
+}
+
+// markTimeout 将结果标记为超时，并记录当前所有协程的堆栈以便定位卡住的位置
+// EN: markTimeout marks the result as timed out and records the stacks of all goroutines to locate the hang.
+func markTimeout(result *TestResult, limit time.Duration) {
+	result.Success = false
+	result.Status = StatusTimeout
+	result.Error = fmt.Sprintf("测试超时 (%s)", limit) // EN: Test timed out
+	result.ActionError = result.Error
+	result.AssertionFailures = nil
+	result.GoroutineDump = goroutineDump()
+}
+
+// goroutineDump 返回所有协程的堆栈
+// EN: goroutineDump returns the stacks of all goroutines.
+func goroutineDump() string {
+	buf := make([]byte, goroutineDumpSize)
+	n := runtime.Stack(buf, true)
+	return string(buf[:n])
+}
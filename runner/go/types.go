@@ -9,70 +9,167 @@ import (
 // TestCase 测试用例定义
 // EN: TestCase defines a test case structure.
 type TestCase struct {
-	Name        string      `json:"name"`        // 测试名称 // EN: Test name
-	Category    string      `json:"category"`    // 分类 // EN: Category
-	Operation   string      `json:"operation"`   // 操作类型 // EN: Operation type
-	Collection  string      `json:"collection"`  // 集合名称 // EN: Collection name
-	Description string      `json:"description"` // 描述 // EN: Description
-	Setup       []SetupStep `json:"setup"`       // 前置步骤 // EN: Setup steps
-	Action      TestAction  `json:"action"`      // 测试动作 // EN: Test action
-	Expected    Expected    `json:"expected"`    // 预期结果 // EN: Expected result
+	Name        string      `json:"name" bson:"name"`                                 // 测试名称 // EN: Test name
+	Category    string      `json:"category" bson:"category"`                         // 分类 // EN: Category
+	Operation   string      `json:"operation" bson:"operation"`                       // 操作类型 // EN: Operation type
+	Collection  string      `json:"collection" bson:"collection"`                     // 集合名称 // EN: Collection name
+	Description string      `json:"description" bson:"description"`                   // 描述 // EN: Description
+	Setup       []SetupStep `json:"setup" bson:"setup"`                               // 前置步骤 // EN: Setup steps
+	Action      TestAction  `json:"action" bson:"action"`                             // 测试动作 // EN: Test action
+	Teardown    []SetupStep `json:"teardown,omitempty" bson:"teardown,omitempty"`     // 清理步骤 // EN: Teardown steps
+	Expected    Expected    `json:"expected" bson:"expected"`                         // 预期结果 // EN: Expected result
+	Comparison  *Comparison `json:"comparison,omitempty" bson:"comparison,omitempty"` // 文档比较配置 // EN: Document comparison settings
+	TimeoutMS   int64       `json:"timeout_ms,omitempty" bson:"timeout_ms,omitempty"` // 超时时间（毫秒），覆盖 --timeout // EN: Timeout in milliseconds, overrides --timeout
+	Verify      *Verify     `json:"verify,omitempty" bson:"verify,omitempty"`         // 动作后的状态校验 // EN: Post-action state verification
+
+	Transaction *Transaction   `json:"transaction,omitempty" bson:"transaction,omitempty"` // 事务定义（动作方法为 transaction 时使用）// EN: Transaction definition (used when the action method is transaction)
+	Steps       []ScenarioStep `json:"steps,omitempty" bson:"steps,omitempty"`             // 场景步骤（动作方法为 steps 时使用）// EN: Scenario steps (used when the action method is steps)
+
+	Reference *ExpectedResult `json:"-" bson:"-"` // 参考结果（MongoDB 或黄金文件）// EN: Reference result (MongoDB or golden file)
+}
+
+// Comparison 文档比较配置
+// EN: Comparison configures how expected documents are compared with returned documents.
+type Comparison struct {
+	Mode    string `json:"mode,omitempty" bson:"mode,omitempty"`       // strict 或 lenient（默认）// EN: strict or lenient (default)
+	Ordered bool   `json:"ordered,omitempty" bson:"ordered,omitempty"` // 是否按顺序比较结果集 // EN: Whether the result set is compared in order
+}
+
+// Verify 动作执行后的状态校验：执行一次查询并比较返回的文档
+// EN: Verify checks the state after the action by running a query and comparing the returned documents.
+type Verify struct {
+	Collection string `json:"collection,omitempty" bson:"collection,omitempty"` // 查询的集合，默认为测试集合 // EN: Collection to query, defaults to the test collection
+	Filter     any    `json:"filter,omitempty" bson:"filter,omitempty"`         // 查询条件 // EN: Query filter
+	Options    any    `json:"options,omitempty" bson:"options,omitempty"`       // 查询选项（sort、projection 等）// EN: Query options (sort, projection, ...)
+	Documents  []any  `json:"documents" bson:"documents"`                       // 预期文档 // EN: Expected documents
 }
 
-// SetupStep 前置步骤
-// EN: SetupStep defines a setup step before test execution.
+// Transaction 事务测试：开启事务，依次执行操作，最后提交或回滚
+// EN: Transaction describes a transaction test: start a transaction, run the operations in order, then commit or abort.
+type Transaction struct {
+	Operations []TxnOperation `json:"operations" bson:"operations"` // 操作列表 // EN: Operations
+	Outcome    string         `json:"outcome" bson:"outcome"`       // commit 或 abort // EN: commit or abort
+}
+
+// TxnOperation 事务测试中的单个操作及其预期结果
+// EN: TxnOperation is a single operation of a transaction test with its expected result.
+type TxnOperation struct {
+	Session    string     `json:"session,omitempty" bson:"session,omitempty"`       // 执行位置：txn（默认）、other（并发的另一个事务）、none（事务外）// EN: Where it runs: txn (default), other (a concurrent transaction), none (outside any transaction)
+	Collection string     `json:"collection,omitempty" bson:"collection,omitempty"` // 目标集合，默认为测试集合 // EN: Target collection, defaults to the test collection
+	Action     TestAction `json:"action" bson:"action"`                             // 操作 // EN: Operation
+	Expected   Expected   `json:"expected" bson:"expected"`                         // 预期结果 // EN: Expected result
+}
+
+// ScenarioStep 场景测试中的单个步骤；动作和预期结果中的 {"$stepRef": "<步骤序号>.<路径>"}
+// 会被替换为先前步骤的结果，例如 "0.inserted_id" 或 "2.documents.0._id"
+// EN: ScenarioStep is a single step of a scenario test; {"$stepRef": "<step index>.<path>"} in the action and expected result
+// EN: is replaced with a result of an earlier step, e.g. "0.inserted_id" or "2.documents.0._id".
+type ScenarioStep struct {
+	Name       string     `json:"name,omitempty" bson:"name,omitempty"`             // 步骤名称 // EN: Step name
+	Collection string     `json:"collection,omitempty" bson:"collection,omitempty"` // 目标集合，默认为测试集合 // EN: Target collection, defaults to the test collection
+	Action     TestAction `json:"action" bson:"action"`                             // 动作 // EN: Action
+	Expected   Expected   `json:"expected" bson:"expected"`                         // 预期结果 // EN: Expected result
+}
+
+// 事务操作的执行位置 // EN: Where a transaction operation runs
+const (
+	SessionTxn   = "txn"   // 测试事务内 // EN: Inside the test transaction
+	SessionOther = "other" // 并发的另一个事务内 // EN: Inside a concurrent transaction
+	SessionNone  = "none"  // 事务外 // EN: Outside any transaction
+)
+
+// SetupStep 前置或清理步骤
+// EN: SetupStep defines a setup or teardown step around test execution.
 type SetupStep struct {
-	Operation string `json:"operation"` // 操作类型 // EN: Operation type
-	Data      any    `json:"data"`      // 操作数据 // EN: Operation data
+	Operation  string `json:"operation" bson:"operation"`                       // 操作类型 // EN: Operation type
+	Collection string `json:"collection,omitempty" bson:"collection,omitempty"` // 目标集合，默认为测试集合 // EN: Target collection, defaults to the test collection
+	Data       any    `json:"data,omitempty" bson:"data,omitempty"`             // 操作数据 // EN: Operation data
 }
 
 // TestAction 测试动作
 // EN: TestAction defines the action to be performed in a test.
 type TestAction struct {
-	Method  string `json:"method"`            // 方法名 // EN: Method name
-	Filter  any    `json:"filter,omitempty"`  // 查询条件 // EN: Query filter
-	Update  any    `json:"update,omitempty"`  // 更新内容 // EN: Update content
-	Doc     any    `json:"doc,omitempty"`     // 文档 // EN: Document
-	Docs    []any  `json:"docs,omitempty"`    // 文档列表 // EN: Document list
-	Options any    `json:"options,omitempty"` // 选项 // EN: Options
+	Method  string `json:"method" bson:"method"`                       // 方法名 // EN: Method name
+	Filter  any    `json:"filter,omitempty" bson:"filter,omitempty"`   // 查询条件 // EN: Query filter
+	Update  any    `json:"update,omitempty" bson:"update,omitempty"`   // 更新内容 // EN: Update content
+	Doc     any    `json:"doc,omitempty" bson:"doc,omitempty"`         // 文档 // EN: Document
+	Docs    []any  `json:"docs,omitempty" bson:"docs,omitempty"`       // 文档列表 // EN: Document list
+	Options any    `json:"options,omitempty" bson:"options,omitempty"` // 选项 // EN: Options
 }
 
 // Expected 预期结果
 // EN: Expected defines the expected result of a test.
 type Expected struct {
-	Count         *int64 `json:"count,omitempty"`          // 预期数量 // EN: Expected count
-	Documents     []any  `json:"documents,omitempty"`      // 预期文档 // EN: Expected documents
-	MatchedCount  *int64 `json:"matched_count,omitempty"`  // 匹配数量 // EN: Matched count
-	ModifiedCount *int64 `json:"modified_count,omitempty"` // 修改数量 // EN: Modified count
-	DeletedCount  *int64 `json:"deleted_count,omitempty"`  // 删除数量 // EN: Deleted count
-	UpsertedID    any    `json:"upserted_id,omitempty"`    // Upsert ID // EN: Upserted ID
-	Error         string `json:"error,omitempty"`          // 预期错误 // EN: Expected error
-	IndexName     string `json:"index_name,omitempty"`     // 索引名称 // EN: Index name
+	Count         *int64 `json:"count,omitempty" bson:"count,omitempty"`                     // 预期数量 // EN: Expected count
+	Documents     []any  `json:"documents,omitempty" bson:"documents,omitempty"`             // 预期文档 // EN: Expected documents
+	MatchedCount  *int64 `json:"matched_count,omitempty" bson:"matched_count,omitempty"`     // 匹配数量 // EN: Matched count
+	ModifiedCount *int64 `json:"modified_count,omitempty" bson:"modified_count,omitempty"`   // 修改数量 // EN: Modified count
+	DeletedCount  *int64 `json:"deleted_count,omitempty" bson:"deleted_count,omitempty"`     // 删除数量 // EN: Deleted count
+	UpsertedID    any    `json:"upserted_id,omitempty" bson:"upserted_id,omitempty"`         // Upsert ID // EN: Upserted ID
+	Error         string `json:"error,omitempty" bson:"error,omitempty"`                     // 预期错误信息中包含的文本，没有错误码时使用 // EN: Text the expected error message contains, used when no error code is given
+	ErrorCode     int32  `json:"error_code,omitempty" bson:"error_code,omitempty"`           // 预期错误码 // EN: Expected error code
+	ErrorCodeName string `json:"error_code_name,omitempty" bson:"error_code_name,omitempty"` // 预期错误码名称 // EN: Expected error code name
+	IndexName     string `json:"index_name,omitempty" bson:"index_name,omitempty"`           // 索引名称 // EN: Index name
 }
 
 // TestResult 测试结果
 // EN: TestResult defines the result of a test execution.
 type TestResult struct {
-	TestName      string   `json:"test_name"`               // 测试名称 // EN: Test name
-	Language      string   `json:"language"`                // 语言 // EN: Language
-	Mode          string   `json:"mode"`                    // 模式 // EN: Mode
-	Success       bool     `json:"success"`                 // 是否成功 // EN: Success status
-	Error         string   `json:"error,omitempty"`         // 错误信息 // EN: Error message
-	Duration      int64    `json:"duration_ms"`             // 耗时（毫秒）// EN: Duration in milliseconds
-	Documents     []bson.M `json:"documents,omitempty"`     // 返回的文档 // EN: Returned documents
-	Count         int64    `json:"count,omitempty"`         // 数量 // EN: Count
-	MatchedCount  int64    `json:"matched_count,omitempty"` // 匹配数量 // EN: Matched count
-	ModifiedCount int64    `json:"modified_count,omitempty"` // 修改数量 // EN: Modified count
-	DeletedCount  int64    `json:"deleted_count,omitempty"` // 删除数量 // EN: Deleted count
-	UpsertedID    any      `json:"upserted_id,omitempty"`   // Upsert ID // EN: Upserted ID
+	TestName      string   `json:"test_name"`                 // 测试名称 // EN: Test name
+	Language      string   `json:"language"`                  // 语言 // EN: Language
+	Mode          string   `json:"mode"`                      // 模式 // EN: Mode
+	Success       bool     `json:"success"`                   // 是否成功 // EN: Success status
+	Status        string   `json:"status"`                    // 状态: passed, failed, skipped, timeout // EN: Status: passed, failed, skipped, timeout
+	SkipReason    string   `json:"skip_reason,omitempty"`     // 跳过原因 // EN: Skip reason
+	Error         string   `json:"error,omitempty"`           // 错误信息 // EN: Error message
+	ActionError   string   `json:"-"`                         // 动作返回的原始错误 // EN: Raw error returned by the action
+	ErrorCode     int32    `json:"error_code,omitempty"`      // 动作错误的错误码 // EN: Error code of the action error
+	ErrorCodeName string   `json:"error_code_name,omitempty"` // 动作错误的错误码名称 // EN: Error code name of the action error
+	Duration      int64    `json:"duration_ms"`               // 耗时（毫秒）// EN: Duration in milliseconds
+	Documents     []bson.M `json:"documents,omitempty"`       // 返回的文档 // EN: Returned documents
+	RawDocuments  []bson.D `json:"-"`                         // 保持字段顺序的返回文档 // EN: Returned documents with field order preserved
+	Count         int64    `json:"count,omitempty"`           // 数量 // EN: Count
+	MatchedCount  int64    `json:"matched_count,omitempty"`   // 匹配数量 // EN: Matched count
+	ModifiedCount int64    `json:"modified_count,omitempty"`  // 修改数量 // EN: Modified count
+	DeletedCount  int64    `json:"deleted_count,omitempty"`   // 删除数量 // EN: Deleted count
+	UpsertedID    any      `json:"upserted_id,omitempty"`     // Upsert ID // EN: Upserted ID
+	InsertedIDs   []any    `json:"inserted_ids,omitempty"`    // 插入文档的 _id // EN: _id values of the inserted documents
+	IndexName     string   `json:"index_name,omitempty"`      // 索引名称 // EN: Index name
+
+	VerifyDocuments    []bson.M `json:"verify_documents,omitempty"` // 状态校验查询返回的文档 // EN: Documents returned by the verification query
+	RawVerifyDocuments []bson.D `json:"-"`                          // 保持字段顺序的状态校验文档 // EN: Verification documents with field order preserved
+
+	Operations []TestResult `json:"operations,omitempty"`  // 事务操作或场景步骤的结果 // EN: Results of the transaction operations or scenario steps
+	FailedStep *int         `json:"failed_step,omitempty"` // 第一个失败的场景步骤序号 // EN: Index of the first failing scenario step
+
+	AssertionFailures []AssertionFailure `json:"assertion_failures,omitempty"` // 断言失败列表 // EN: Assertion failures
+	GoroutineDump     string             `json:"goroutine_dump,omitempty"`     // 超时时的协程堆栈 // EN: Goroutine dump taken on timeout
+}
+
+// 测试状态 // EN: Test statuses
+const (
+	StatusPassed  = "passed"  // 通过 // EN: Passed
+	StatusFailed  = "failed"  // 失败 // EN: Failed
+	StatusSkipped = "skipped" // 被筛选条件跳过 // EN: Skipped by the test filter
+	StatusTimeout = "timeout" // 超时 // EN: Timed out
+)
+
+// AssertionFailure 单个断言失败的详情
+// EN: AssertionFailure describes a single failed assertion.
+type AssertionFailure struct {
+	Field        string `json:"field"`                   // 字段路径 // EN: Field path
+	Expected     any    `json:"expected"`                // 预期值 // EN: Expected value
+	Actual       any    `json:"actual"`                  // 实际值 // EN: Actual value
+	ExpectedType string `json:"expected_type,omitempty"` // 预期值的 BSON 类型 // EN: BSON type of the expected value
+	ActualType   string `json:"actual_type,omitempty"`   // 实际值的 BSON 类型 // EN: BSON type of the actual value
 }
 
 // TestSuite 测试套件
 // EN: TestSuite defines a collection of test cases.
 type TestSuite struct {
-	Version   string     `json:"version"`   // 版本 // EN: Version
-	Generated string     `json:"generated"` // 生成时间 // EN: Generated time
-	Tests     []TestCase `json:"tests"`     // 测试用例列表 // EN: Test case list
+	Version   string     `json:"version" bson:"version"`     // 版本 // EN: Version
+	Generated string     `json:"generated" bson:"generated"` // 生成时间 // EN: Generated time
+	Tests     []TestCase `json:"tests" bson:"tests"`         // 测试用例列表 // EN: Test case list
 }
 
 // ResultsFile 结果文件
@@ -87,8 +184,9 @@ type ResultsFile struct {
 // Summary 摘要
 // EN: Summary defines the summary of test results.
 type Summary struct {
-	Total   int `json:"total"`   // 总数 // EN: Total count
-	Passed  int `json:"passed"`  // 通过数 // EN: Passed count
-	Failed  int `json:"failed"`  // 失败数 // EN: Failed count
-	Skipped int `json:"skipped"` // 跳过数 // EN: Skipped count
+	Total    int `json:"total"`               // 总数 // EN: Total count
+	Passed   int `json:"passed"`              // 通过数 // EN: Passed count
+	Failed   int `json:"failed"`              // 失败数 // EN: Failed count
+	Skipped  int `json:"skipped"`             // 跳过数 // EN: Skipped count
+	TimedOut int `json:"timed_out,omitempty"` // 超时数（已计入失败数）// EN: Timed-out count (included in the failed count)
 }
@@ -5,6 +5,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/monolite/monodb/engine"
@@ -23,39 +24,78 @@ type WireRunner struct {
 	addr   string             // 服务器地址 // EN: Server address
 }
 
-// NewWireRunner 创建 Wire 运行器
-// EN: NewWireRunner creates a Wire runner.
+// 服务器启动参数 // EN: Server startup parameters
+const (
+	readyTimeout  = 10 * time.Second       // 等待服务器就绪的最长时间 // EN: Maximum time to wait for the server to become ready
+	readyInterval = 50 * time.Millisecond  // 就绪探测间隔 // EN: Interval between readiness probes
+	probeTimeout  = 500 * time.Millisecond // 单次 hello 探测的超时 // EN: Timeout of a single hello probe
+	startAttempts = 3                      // 动态端口时的启动尝试次数 // EN: Startup attempts with a dynamic port
+)
+
+// NewWireRunner 创建 Wire 运行器；port 为 0 时自动选择空闲端口
+// EN: NewWireRunner creates a Wire runner; a free port is chosen automatically when port is 0.
 func NewWireRunner(dbPath string, port int) (*WireRunner, error) {
 	db, err := engine.OpenDatabase(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("打开数据库失败: %w", err) // EN: Failed to open database
 	}
 
+	// 动态端口可能在选出后被其他进程占用，因此允许重试
+	// EN: A dynamic port may be taken by another process after it was chosen, so retries are allowed
+	attempts := 1
+	if port == 0 {
+		attempts = startAttempts
+	}
+	for i := 0; ; i++ {
+		runner, err := startWireRunner(db, port)
+		if err == nil {
+			return runner, nil
+		}
+		if i+1 >= attempts {
+			db.Close()
+			return nil, err
+		}
+	}
+}
+
+// startWireRunner 启动 Wire Protocol 服务器并等待其就绪
+// EN: startWireRunner starts the Wire Protocol server and waits until it is ready.
+func startWireRunner(db *engine.Database, port int) (*WireRunner, error) {
+	if port == 0 {
+		free, err := freePort()
+		if err != nil {
+			return nil, fmt.Errorf("查找空闲端口失败: %w", err) // EN: Failed to find a free port
+		}
+		port = free
+	}
+
 	addr := fmt.Sprintf(":%d", port)
 	server := protocol.NewServer(addr, db)
 
-	// 启动服务器 // EN: Start server
+	// 启动服务器，启动错误通过通道返回 // EN: Start server; startup errors are returned through the channel
+	started := make(chan error, 1)
 	go func() {
-		if err := server.Start(); err != nil {
-			fmt.Printf("服务器启动失败: %v\n", err) // EN: Server start failed
-		}
+		started <- server.Start()
 	}()
 
-	// 等待服务器启动 // EN: Wait for server to start
-	time.Sleep(100 * time.Millisecond)
-
 	// 连接客户端 // EN: Connect client
-	ctx := context.Background()
 	clientOpts := options.Client().
 		ApplyURI(fmt.Sprintf("mongodb://localhost:%d", port)).
 		SetDirect(true)
 
-	client, err := mongo.Connect(ctx, clientOpts)
+	client, err := mongo.Connect(context.Background(), clientOpts)
 	if err != nil {
-		db.Close()
+		server.Stop()
 		return nil, fmt.Errorf("连接客户端失败: %w", err) // EN: Failed to connect client
 	}
 
+	// 等待服务器就绪 // EN: Wait for server to become ready
+	if err := waitReady(client, started); err != nil {
+		client.Disconnect(context.Background())
+		server.Stop()
+		return nil, fmt.Errorf("端口 %d: %w", port, err) // EN: Port %d
+	}
+
 	return &WireRunner{
 		db:     db,
 		server: server,
@@ -64,6 +104,44 @@ func NewWireRunner(dbPath string, port int) (*WireRunner, error) {
 	}, nil
 }
 
+// waitReady 反复发送 hello 直到服务器响应、启动失败或超过截止时间
+// EN: waitReady sends hello repeatedly until the server answers, fails to start or the deadline expires.
+func waitReady(client *mongo.Client, started <-chan error) error {
+	deadline := time.Now().Add(readyTimeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-started:
+			if err != nil {
+				return fmt.Errorf("服务器启动失败: %w", err) // EN: Server start failed
+			}
+			// Start 正常返回，继续探测 // EN: Start returned normally, keep probing
+			started = nil
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		lastErr = client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Err()
+		cancel()
+		if lastErr == nil {
+			return nil
+		}
+		time.Sleep(readyInterval)
+	}
+	return fmt.Errorf("等待服务器就绪超时 (%s): %v", readyTimeout, lastErr) // EN: Timed out waiting for the server to become ready
+}
+
+// freePort 向系统申请一个当前空闲的 TCP 端口
+// EN: freePort asks the system for a currently free TCP port.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
 // Close 关闭连接
 // EN: Close closes all connections.
 func (r *WireRunner) Close() error {
@@ -90,66 +168,142 @@ func (r *WireRunner) RunTest(tc TestCase) TestResult {
 		Mode:     "wire",
 	}
 
-	ctx := context.Background()
-	col := r.client.Database("test").Collection(tc.Collection)
+	limit := testTimeout(tc)
+	ctx, cancel := contextWithLimit(context.Background(), limit)
+	defer cancel()
+
+	db := r.client.Database("test")
+	col := db.Collection(tc.Collection)
 
-	// 执行前置步骤 // EN: Execute setup steps
-	if err := r.executeSetup(ctx, col, tc); err != nil {
+	// 执行前置步骤，成功后执行测试动作并校验预期结果
+	// EN: Execute setup steps, then the test action, and check the expected result
+	if err := r.executeSteps(ctx, db, tc.Collection, tc.Setup); err != nil {
 		result.Error = fmt.Sprintf("Setup 失败: %v", err) // EN: Setup failed
-		result.Duration = time.Since(start).Milliseconds()
-		return result
+		result.ActionError = result.Error
+	} else {
+		err := r.executeAction(ctx, col, tc, &result)
+		evaluateResult(tc, &result, err)
+		if tc.Transaction != nil {
+			evaluateTransaction(tc, &result)
+		}
+		if tc.Steps != nil {
+			evaluateScenario(tc, &result)
+		}
+		if tc.Verify != nil {
+			r.executeVerify(ctx, db, tc, &result)
+		}
 	}
 
-	// 执行测试动作 // EN: Execute test action
-	if err := r.executeAction(ctx, col, tc, &result); err != nil {
-		result.Error = err.Error()
-		result.Duration = time.Since(start).Milliseconds()
-		if tc.Expected.Error != "" {
-			result.Success = true
-		}
-		return result
+	// 超过截止时间的测试标记为超时 // EN: Tests that exceeded the deadline are marked as timed out
+	if ctx.Err() == context.DeadlineExceeded {
+		markTimeout(&result, limit)
+	}
+
+	// 无论结果如何都执行清理步骤，使用独立的截止时间
+	// EN: Always execute teardown steps regardless of the outcome, with a separate deadline
+	teardownCtx, cancelTeardown := contextWithLimit(context.Background(), limit)
+	defer cancelTeardown()
+	if err := r.executeSteps(teardownCtx, db, tc.Collection, tc.Teardown); err != nil {
+		recordTeardownFailure(&result, err)
 	}
 
-	result.Success = true
 	result.Duration = time.Since(start).Milliseconds()
 	return result
 }
 
-// executeSetup 执行前置步骤
-// EN: executeSetup executes the setup steps.
-func (r *WireRunner) executeSetup(ctx context.Context, col *mongo.Collection, tc TestCase) error {
-	if len(tc.Setup) == 0 {
-		return nil
+// executeVerify 在动作之后查询集合并校验其状态
+// EN: executeVerify queries the collection after the action and verifies its state.
+func (r *WireRunner) executeVerify(ctx context.Context, db *mongo.Database, tc TestCase, result *TestResult) {
+	name := tc.Collection
+	if tc.Verify.Collection != "" {
+		name = tc.Verify.Collection
+	}
+	col := db.Collection(name)
+	query := TestCase{Action: TestAction{Method: "find", Filter: tc.Verify.Filter, Options: tc.Verify.Options}}
+	var verifyResult TestResult
+	err := r.executeFind(ctx, col, query, &verifyResult)
+	evaluateVerify(tc, result, verifyResult.RawDocuments, err)
+}
+
+// executeSteps 执行前置或清理步骤
+// EN: executeSteps executes setup or teardown steps.
+func (r *WireRunner) executeSteps(ctx context.Context, db *mongo.Database, collection string, steps []SetupStep) error {
+	for _, step := range steps {
+		name := collection
+		if step.Collection != "" {
+			name = step.Collection
+		}
+		if err := r.executeStep(ctx, db, name, step); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// executeStep 在指定集合上执行单个步骤
+// EN: executeStep executes a single step on the given collection.
+func (r *WireRunner) executeStep(ctx context.Context, db *mongo.Database, name string, step SetupStep) error {
+	col := db.Collection(name)
 
-	for _, step := range tc.Setup {
-		switch step.Operation {
-		case "insert":
-			doc := toBsonD(step.Data)
-			if _, err := col.InsertOne(ctx, doc); err != nil {
-				return err
+	switch step.Operation {
+	case "insert":
+		doc := toBsonD(step.Data)
+		if _, err := col.InsertOne(ctx, doc); err != nil {
+			return err
+		}
+	case "insertMany":
+		docs := toBsonDSlice(toSlice(step.Data))
+		ifaces := make([]interface{}, len(docs))
+		for i, d := range docs {
+			ifaces[i] = d
+		}
+		if _, err := col.InsertMany(ctx, ifaces); err != nil {
+			return err
+		}
+	case "deleteMany":
+		filter := toBsonD(step.Data)
+		if filter == nil {
+			filter = bson.D{}
+		}
+		if _, err := col.DeleteMany(ctx, filter); err != nil {
+			return err
+		}
+	case "createIndex":
+		opts := toBsonD(step.Data)
+		keysRaw := getField(opts, "keys")
+		keys := toBsonD(keysRaw)
+		indexModel := mongo.IndexModel{Keys: keys}
+		if indexOpts := getFieldD(opts, "options"); indexOpts != nil {
+			if v := getField(indexOpts, "unique"); v != nil {
+				unique := v.(bool)
+				indexModel.Options = options.Index().SetUnique(unique)
 			}
-		case "createIndex":
-			opts := toBsonD(step.Data)
-			keysRaw := getField(opts, "keys")
-			keys := toBsonD(keysRaw)
-			indexModel := mongo.IndexModel{Keys: keys}
-			if indexOpts := getFieldD(opts, "options"); indexOpts != nil {
-				if v := getField(indexOpts, "unique"); v != nil {
-					unique := v.(bool)
-					indexModel.Options = options.Index().SetUnique(unique)
+			if v := getField(indexOpts, "name"); v != nil {
+				if indexModel.Options == nil {
+					indexModel.Options = options.Index()
 				}
-				if v := getField(indexOpts, "name"); v != nil {
-					if indexModel.Options == nil {
-						indexModel.Options = options.Index()
-					}
-					indexModel.Options.SetName(v.(string))
-				}
-			}
-			if _, err := col.Indexes().CreateOne(ctx, indexModel); err != nil {
-				return err
+				indexModel.Options.SetName(v.(string))
 			}
 		}
+		if _, err := col.Indexes().CreateOne(ctx, indexModel); err != nil {
+			return err
+		}
+	case "dropIndex":
+		indexName, _ := getField(toBsonD(step.Data), "name").(string)
+		if _, err := col.Indexes().DropOne(ctx, indexName); err != nil {
+			return err
+		}
+	case "drop":
+		// 驱动会忽略集合不存在的错误 // EN: The driver ignores the namespace-not-found error
+		if err := col.Drop(ctx); err != nil {
+			return err
+		}
+	case "createCollection":
+		if err := db.CreateCollection(ctx, name); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("未知步骤: %s", step.Operation) // EN: Unknown step
 	}
 	return nil
 }
@@ -186,20 +340,120 @@ func (r *WireRunner) executeAction(ctx context.Context, col *mongo.Collection, t
 		return r.executeListIndexes(ctx, col, tc, result)
 	case "dropIndex":
 		return r.executeDropIndex(ctx, col, tc, result)
+	case "findAndModify":
+		return r.executeFindAndModify(ctx, col, tc, result)
+	case "findOneAndUpdate", "findOneAndReplace", "findOneAndDelete":
+		return r.executeFindOneAnd(ctx, col, tc, result)
+	case "countDocuments":
+		return r.executeCountDocuments(ctx, col, tc, result)
+	case "estimatedDocumentCount":
+		n, err := col.EstimatedDocumentCount(ctx)
+		result.Count = n
+		return err
+	case "bulkWrite":
+		return r.executeBulkWrite(ctx, col, tc, result)
+	case "drop":
+		return col.Drop(ctx)
+	case "listCollections":
+		return r.executeListCollections(ctx, col.Database(), tc, result)
+	case "transaction":
+		return r.executeTransaction(ctx, col.Database(), tc, result)
+	case "steps":
+		db := col.Database()
+		result.Operations = runScenario(tc, "wire", func(step TestCase, stepResult *TestResult) error {
+			return r.executeAction(ctx, db.Collection(step.Collection), step, stepResult)
+		})
+		return nil
 	default:
 		return fmt.Errorf("未知方法: %s", tc.Action.Method) // EN: Unknown method
 	}
 }
 
+// wireMethods WireRunner 可执行的动作方法，与 executeAction 保持一致；事务中同样可用
+// EN: wireMethods lists the action methods WireRunner can execute, kept in sync with executeAction; they are available inside transactions too.
+var wireMethods = methodSet(
+	"insertOne", "insertMany", "find", "findOne", "updateOne", "updateMany",
+	"deleteOne", "deleteMany", "replaceOne", "findAndModify", "distinct", "aggregate",
+	"createIndex", "listIndexes", "dropIndex",
+	"findOneAndUpdate", "findOneAndReplace", "findOneAndDelete",
+	"countDocuments", "estimatedDocumentCount", "bulkWrite", "drop", "listCollections",
+	"transaction", "steps",
+)
+
+// executeTransaction 在会话中显式开启事务并依次执行操作，最后按测试定义提交或回滚；
+// 不使用 WithTransaction，以免瞬时错误触发的自动重试掩盖写冲突，每个操作只执行一次，断言的是第一次出现的错误。
+// 并发事务中的操作使用另一个会话，该事务总是在最后回滚
+// EN: executeTransaction explicitly starts a transaction in a session, runs the operations in order and commits or aborts as the test defines;
+// EN: WithTransaction is not used because its automatic retries on transient errors would hide write conflicts, so every operation runs once
+// EN: and the first error is what gets asserted. Operations of the concurrent transaction use a second session, whose transaction is always aborted at the end.
+func (r *WireRunner) executeTransaction(ctx context.Context, db *mongo.Database, tc TestCase, result *TestResult) error {
+	if tc.Transaction == nil {
+		return fmt.Errorf("缺少事务定义") // EN: Missing transaction definition
+	}
+	session, err := r.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	other, err := r.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer other.EndSession(ctx)
+
+	if err := session.StartTransaction(); err != nil {
+		return err
+	}
+	if err := other.StartTransaction(); err != nil {
+		session.AbortTransaction(ctx)
+		return err
+	}
+	txnCtx := mongo.NewSessionContext(ctx, session)
+	otherCtx := mongo.NewSessionContext(ctx, other)
+
+	for _, op := range tc.Transaction.Operations {
+		name := tc.Collection
+		if op.Collection != "" {
+			name = op.Collection
+		}
+		opCase := TestCase{Collection: name, Action: op.Action}
+		opResult := TestResult{TestName: op.Action.Method, Language: "go", Mode: "wire"}
+
+		var opCtx context.Context = txnCtx
+		switch op.Session {
+		case SessionNone:
+			opCtx = ctx
+		case SessionOther:
+			opCtx = otherCtx
+		}
+
+		opErr := r.executeAction(opCtx, db.Collection(name), opCase, &opResult)
+		if opErr != nil {
+			recordActionError(&opResult, opErr)
+		}
+		opResult.Success = opErr == nil
+		result.Operations = append(result.Operations, opResult)
+	}
+
+	// 冲突后并发事务可能已被服务端回滚，忽略回滚错误
+	// EN: The concurrent transaction may already have been aborted by the server after a conflict, so abort errors are ignored
+	other.AbortTransaction(ctx)
+	if tc.Transaction.Outcome == "abort" {
+		return session.AbortTransaction(ctx)
+	}
+	return session.CommitTransaction(ctx)
+}
+
 // executeInsertOne 执行插入单个文档
 // EN: executeInsertOne executes insert one document.
 func (r *WireRunner) executeInsertOne(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
 	doc := toBsonD(tc.Action.Doc)
-	_, err := col.InsertOne(ctx, doc)
+	res, err := col.InsertOne(ctx, doc)
 	if err != nil {
 		return err
 	}
 	result.Count = 1
+	result.InsertedIDs = []any{res.InsertedID}
 	return nil
 }
 
@@ -216,6 +470,7 @@ func (r *WireRunner) executeInsertMany(ctx context.Context, col *mongo.Collectio
 		return err
 	}
 	result.Count = int64(len(res.InsertedIDs))
+	result.InsertedIDs = res.InsertedIDs
 	return nil
 }
 
@@ -250,12 +505,12 @@ func (r *WireRunner) executeFind(ctx context.Context, col *mongo.Collection, tc
 	}
 	defer cursor.Close(ctx)
 
-	var docs []bson.M
+	var docs []bson.D
 	if err := cursor.All(ctx, &docs); err != nil {
 		return err
 	}
 	result.Count = int64(len(docs))
-	result.Documents = docs
+	result.setDocuments(docs)
 	return nil
 }
 
@@ -264,7 +519,7 @@ func (r *WireRunner) executeFind(ctx context.Context, col *mongo.Collection, tc
 func (r *WireRunner) executeFindOne(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
 	filter := toBsonD(tc.Action.Filter)
 
-	var doc bson.M
+	var doc bson.D
 	err := col.FindOne(ctx, filter).Decode(&doc)
 	if err == mongo.ErrNoDocuments {
 		result.Count = 0
@@ -274,7 +529,7 @@ func (r *WireRunner) executeFindOne(ctx context.Context, col *mongo.Collection,
 		return err
 	}
 	result.Count = 1
-	result.Documents = []bson.M{doc}
+	result.setDocuments([]bson.D{doc})
 	return nil
 }
 
@@ -284,15 +539,7 @@ func (r *WireRunner) executeUpdateOne(ctx context.Context, col *mongo.Collection
 	filter := toBsonD(tc.Action.Filter)
 	update := toBsonD(tc.Action.Update)
 
-	updateOpts := options.Update()
-	if tc.Action.Options != nil {
-		opts := toBsonD(tc.Action.Options)
-		if v := getField(opts, "upsert"); v != nil {
-			updateOpts.SetUpsert(v.(bool))
-		}
-	}
-
-	res, err := col.UpdateOne(ctx, filter, update, updateOpts)
+	res, err := col.UpdateOne(ctx, filter, update, driverUpdateOptions(tc.Action.Options))
 	if err != nil {
 		return err
 	}
@@ -308,15 +555,27 @@ func (r *WireRunner) executeUpdateMany(ctx context.Context, col *mongo.Collectio
 	filter := toBsonD(tc.Action.Filter)
 	update := toBsonD(tc.Action.Update)
 
-	res, err := col.UpdateMany(ctx, filter, update)
+	res, err := col.UpdateMany(ctx, filter, update, driverUpdateOptions(tc.Action.Options))
 	if err != nil {
 		return err
 	}
 	result.MatchedCount = res.MatchedCount
 	result.ModifiedCount = res.ModifiedCount
+	result.UpsertedID = res.UpsertedID
 	return nil
 }
 
+// driverUpdateOptions 将测试用例的更新选项转换为驱动选项
+// EN: driverUpdateOptions converts the update options of a test case into driver options.
+func driverUpdateOptions(opts any) *options.UpdateOptions {
+	upsert, arrayFilters := updateOptions(opts)
+	updateOpts := options.Update().SetUpsert(upsert)
+	if arrayFilters != nil {
+		updateOpts.SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+	}
+	return updateOpts
+}
+
 // executeDeleteOne 执行删除单个文档
 // EN: executeDeleteOne executes delete one document.
 func (r *WireRunner) executeDeleteOne(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
@@ -346,12 +605,220 @@ func (r *WireRunner) executeDeleteMany(ctx context.Context, col *mongo.Collectio
 func (r *WireRunner) executeReplaceOne(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
 	filter := toBsonD(tc.Action.Filter)
 	replacement := toBsonD(tc.Action.Doc)
-	res, err := col.ReplaceOne(ctx, filter, replacement)
+	upsert, _ := getField(toBsonD(tc.Action.Options), "upsert").(bool)
+	res, err := col.ReplaceOne(ctx, filter, replacement, options.Replace().SetUpsert(upsert))
 	if err != nil {
 		return err
 	}
 	result.MatchedCount = res.MatchedCount
 	result.ModifiedCount = res.ModifiedCount
+	result.UpsertedID = res.UpsertedID
+	return nil
+}
+
+// executeFindAndModify 通过 findAndModify 命令执行查找并修改，与 APIRunner 的语义一致
+// EN: executeFindAndModify runs the findAndModify command, matching the semantics of APIRunner.
+func (r *WireRunner) executeFindAndModify(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
+	query := toBsonD(tc.Action.Filter)
+	if query == nil {
+		query = bson.D{}
+	}
+	cmd := bson.D{{Key: "findAndModify", Value: col.Name()}, {Key: "query", Value: query}}
+	if tc.Action.Update != nil {
+		cmd = append(cmd, bson.E{Key: "update", Value: toBsonD(tc.Action.Update)})
+	}
+	opts := toBsonD(tc.Action.Options)
+	for _, key := range []string{"new", "upsert", "remove"} {
+		if v := getField(opts, key); v != nil {
+			cmd = append(cmd, bson.E{Key: key, Value: v})
+		}
+	}
+
+	var reply struct {
+		Value bson.D `bson:"value"`
+	}
+	if err := col.Database().RunCommand(ctx, cmd).Decode(&reply); err != nil {
+		return err
+	}
+	if reply.Value != nil {
+		result.Count = 1
+		result.setDocuments([]bson.D{reply.Value})
+	}
+	return nil
+}
+
+// executeFindOneAnd 执行 findOneAndUpdate、findOneAndReplace 或 findOneAndDelete
+// EN: executeFindOneAnd executes findOneAndUpdate, findOneAndReplace or findOneAndDelete.
+func (r *WireRunner) executeFindOneAnd(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
+	filter := toBsonD(tc.Action.Filter)
+	if filter == nil {
+		filter = bson.D{}
+	}
+	opts := toBsonD(tc.Action.Options)
+	returnDoc := options.Before
+	if getField(opts, "returnDocument") == "after" {
+		returnDoc = options.After
+	}
+	upsert, _ := getField(opts, "upsert").(bool)
+	sortSpec, projection := getField(opts, "sort"), getField(opts, "projection")
+
+	var res *mongo.SingleResult
+	switch tc.Action.Method {
+	case "findOneAndUpdate":
+		findOpts := options.FindOneAndUpdate().SetReturnDocument(returnDoc).SetUpsert(upsert)
+		if sortSpec != nil {
+			findOpts.SetSort(toBsonD(sortSpec))
+		}
+		if projection != nil {
+			findOpts.SetProjection(toBsonD(projection))
+		}
+		res = col.FindOneAndUpdate(ctx, filter, toBsonD(tc.Action.Update), findOpts)
+	case "findOneAndReplace":
+		findOpts := options.FindOneAndReplace().SetReturnDocument(returnDoc).SetUpsert(upsert)
+		if sortSpec != nil {
+			findOpts.SetSort(toBsonD(sortSpec))
+		}
+		if projection != nil {
+			findOpts.SetProjection(toBsonD(projection))
+		}
+		res = col.FindOneAndReplace(ctx, filter, toBsonD(tc.Action.Doc), findOpts)
+	default:
+		findOpts := options.FindOneAndDelete()
+		if sortSpec != nil {
+			findOpts.SetSort(toBsonD(sortSpec))
+		}
+		if projection != nil {
+			findOpts.SetProjection(toBsonD(projection))
+		}
+		res = col.FindOneAndDelete(ctx, filter, findOpts)
+	}
+
+	var doc bson.D
+	err := res.Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		result.Count = 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	result.Count = 1
+	result.setDocuments([]bson.D{doc})
+	return nil
+}
+
+// executeCountDocuments 统计匹配过滤条件的文档数，支持 skip 和 limit
+// EN: executeCountDocuments counts the documents matching the filter, honouring skip and limit.
+func (r *WireRunner) executeCountDocuments(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
+	filter := toBsonD(tc.Action.Filter)
+	if filter == nil {
+		filter = bson.D{}
+	}
+	opts := toBsonD(tc.Action.Options)
+	countOpts := options.Count()
+	if v := getField(opts, "skip"); v != nil {
+		countOpts.SetSkip(toInt64(v))
+	}
+	if v := getField(opts, "limit"); v != nil {
+		countOpts.SetLimit(toInt64(v))
+	}
+	n, err := col.CountDocuments(ctx, filter, countOpts)
+	if err != nil {
+		return err
+	}
+	result.Count = n
+	return nil
+}
+
+// executeBulkWrite 将 {insertOne: {document}}、{updateOne: {filter, update, upsert}} 等操作转换为驱动的写入模型后批量执行；
+// ordered 默认为真
+// EN: executeBulkWrite converts operations such as {insertOne: {document}} or {updateOne: {filter, update, upsert}} into driver write models and runs them as one bulk write;
+// EN: ordered defaults to true.
+func (r *WireRunner) executeBulkWrite(ctx context.Context, col *mongo.Collection, tc TestCase, result *TestResult) error {
+	opts := toBsonD(tc.Action.Options)
+	ordered := true
+	if v, ok := getField(opts, "ordered").(bool); ok {
+		ordered = v
+	}
+
+	var models []mongo.WriteModel
+	for _, raw := range toSlice(getField(opts, "operations")) {
+		model, err := bulkWriteModel(toBsonD(raw))
+		if err != nil {
+			return err
+		}
+		models = append(models, model)
+	}
+
+	res, err := col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(ordered))
+	if res != nil {
+		result.Count = res.InsertedCount
+		result.MatchedCount = res.MatchedCount
+		result.ModifiedCount = res.ModifiedCount
+		result.DeletedCount = res.DeletedCount
+		// 按操作顺序取第一个 upsert 的 _id // EN: Take the _id of the first upsert in operation order
+		first := int64(-1)
+		for i, id := range res.UpsertedIDs {
+			if first < 0 || i < first {
+				first, result.UpsertedID = i, id
+			}
+		}
+	}
+	return err
+}
+
+// bulkWriteModel 将单个批量写入操作转换为驱动的写入模型
+// EN: bulkWriteModel converts a single bulk write operation into a driver write model.
+func bulkWriteModel(op bson.D) (mongo.WriteModel, error) {
+	if len(op) != 1 {
+		return nil, fmt.Errorf("批量写入操作必须只有一个键: %v", op) // EN: A bulk write operation must have exactly one key
+	}
+	spec := toBsonD(op[0].Value)
+	filter := getFieldD(spec, "filter")
+	if filter == nil {
+		filter = bson.D{}
+	}
+	upsert := getField(spec, "upsert") == true
+
+	switch op[0].Key {
+	case "insertOne":
+		return mongo.NewInsertOneModel().SetDocument(getFieldD(spec, "document")), nil
+	case "updateOne":
+		model := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(getFieldD(spec, "update")).SetUpsert(upsert)
+		if v := toSlice(getField(spec, "arrayFilters")); v != nil {
+			model.SetArrayFilters(options.ArrayFilters{Filters: v})
+		}
+		return model, nil
+	case "updateMany":
+		model := mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(getFieldD(spec, "update")).SetUpsert(upsert)
+		if v := toSlice(getField(spec, "arrayFilters")); v != nil {
+			model.SetArrayFilters(options.ArrayFilters{Filters: v})
+		}
+		return model, nil
+	case "replaceOne":
+		return mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(getFieldD(spec, "replacement")).SetUpsert(upsert), nil
+	case "deleteOne":
+		return mongo.NewDeleteOneModel().SetFilter(filter), nil
+	case "deleteMany":
+		return mongo.NewDeleteManyModel().SetFilter(filter), nil
+	default:
+		return nil, fmt.Errorf("未知的批量写入操作: %s", op[0].Key) // EN: Unknown bulk write operation
+	}
+}
+
+// executeListCollections 列出集合名称，按名称排序后以 {name} 文档返回
+// EN: executeListCollections lists the collection names and returns them as {name} documents sorted by name.
+func (r *WireRunner) executeListCollections(ctx context.Context, db *mongo.Database, tc TestCase, result *TestResult) error {
+	filter := toBsonD(tc.Action.Filter)
+	if filter == nil {
+		filter = bson.D{}
+	}
+	names, err := db.ListCollectionNames(ctx, filter)
+	if err != nil {
+		return err
+	}
+	result.Count = int64(len(names))
+	result.setDocuments(collectionDocs(names))
 	return nil
 }
 
@@ -382,12 +849,12 @@ func (r *WireRunner) executeAggregate(ctx context.Context, col *mongo.Collection
 	}
 	defer cursor.Close(ctx)
 
-	var docs []bson.M
+	var docs []bson.D
 	if err := cursor.All(ctx, &docs); err != nil {
 		return err
 	}
 	result.Count = int64(len(docs))
-	result.Documents = docs
+	result.setDocuments(docs)
 	return nil
 }
 
@@ -439,7 +906,8 @@ func (r *WireRunner) executeCreateIndex(ctx context.Context, col *mongo.Collecti
 		return err
 	}
 	result.Count = 1
-	result.Documents = []bson.M{{"indexName": name}}
+	result.IndexName = name
+	result.setDocuments([]bson.D{{{Key: "indexName", Value: name}}})
 	return nil
 }
 
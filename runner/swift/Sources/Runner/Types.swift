@@ -151,6 +151,12 @@ struct TestResult: Codable {
     /// 插入的文档ID（可选）
     /// EN: Upserted document ID (optional)
     var upserted_id: AnyCodable?
+    /// 状态，跳过的测试为 skipped（可选）
+    /// EN: Status, skipped for skipped tests (optional)
+    var status: String?
+    /// 跳过原因（可选）
+    /// EN: Skip reason (optional)
+    var skip_reason: String?
 }
 
 /// 结果文件结构体，包含所有测试结果和汇总信息
@@ -97,14 +97,35 @@ struct Runner: ParsableCommand {
             return
         }
 
-        let testcasesData = try Data(contentsOf: URL(fileURLWithPath: testcasesPath))
+        let rawData = try Data(contentsOf: URL(fileURLWithPath: testcasesPath))
+        let testcasesData = try relaxExtendedJSON(rawData)
         let decoder = JSONDecoder()
         let suite = try decoder.decode(TestSuite.self, from: testcasesData)
+        let unsupported = try unsupportedTypes(testcasesData)
         print("加载了 \(suite.tests.count) 个测试用例") // EN: Loaded N test cases
 
         var results: [TestResult] = []
         var passed = 0
         var failed = 0
+        var skipped = 0
+
+        // 测试用例使用了无法表示的类型时记录跳过结果 // EN: Record a skipped result when the test case uses a type that cannot be represented
+        func skip(_ tc: TestCase) -> Bool {
+            guard let wrapper = unsupported[tc.name] else { return false }
+            let reason = "不支持的 Extended JSON 类型 \(wrapper)" // EN: Unsupported Extended JSON type
+            results.append(TestResult(
+                test_name: tc.name,
+                language: "swift",
+                mode: mode,
+                success: false,
+                duration_ms: 0,
+                status: "skipped",
+                skip_reason: reason
+            ))
+            skipped += 1
+            print("  - 跳过: \(reason)") // EN: Skipped
+            return true
+        }
 
         if mode == "api" {
             // API 模式：直接调用 MonoLiteSwift API
@@ -114,6 +135,7 @@ struct Runner: ParsableCommand {
 
             for (index, tc) in suite.tests.enumerated() {
                 print("[\(index + 1)/\(suite.tests.count)] 测试: \(tc.name)") // EN: Test
+                if skip(tc) { continue }
 
                 let result = await runner.runTest(tc)
                 results.append(result)
@@ -136,6 +158,7 @@ struct Runner: ParsableCommand {
 
             for (index, tc) in suite.tests.enumerated() {
                 print("[\(index + 1)/\(suite.tests.count)] 测试: \(tc.name)") // EN: Test
+                if skip(tc) { continue }
 
                 let result = await runner.runTest(tc)
                 results.append(result)
@@ -164,7 +187,7 @@ struct Runner: ParsableCommand {
                 total: results.count,
                 passed: passed,
                 failed: failed,
-                skipped: 0
+                skipped: skipped
             )
         )
 
@@ -179,10 +202,76 @@ struct Runner: ParsableCommand {
         try outputData.write(to: URL(fileURLWithPath: outputPath))
 
         print("=== 测试完成 ===") // EN: Tests completed
-        print("通过: \(passed), 失败: \(failed), 总计: \(results.count)") // EN: Passed, Failed, Total
+        print("通过: \(passed), 失败: \(failed), 跳过: \(skipped), 总计: \(results.count)") // EN: Passed, Failed, Skipped, Total
         print("结果已保存到: \(outputPath)") // EN: Results saved to
     }
 
+    /// 将 Extended JSON v2 中的数值包装（$numberInt、$numberLong、$numberDouble）展开为普通 JSON 数值；
+    /// 其余类型包装保持不变，使用它们的测试由 unsupportedTypes 找出并跳过
+    /// EN: Unwrap Extended JSON v2 number wrappers ($numberInt, $numberLong, $numberDouble) into plain JSON numbers;
+    /// EN: other type wrappers are kept, and the tests using them are found by unsupportedTypes and skipped
+    /// - Parameter data: 原始 JSON 数据 / Raw JSON data
+    /// - Returns: 展开后的 JSON 数据 / Unwrapped JSON data
+    private static func relaxExtendedJSON(_ data: Data) throws -> Data {
+        func relax(_ value: Any) -> Any {
+            if let dict = value as? [String: Any] {
+                if dict.count == 1, let entry = dict.first, let text = entry.value as? String {
+                    switch entry.key {
+                    case "$numberInt", "$numberLong":
+                        if let n = Int64(text) { return n }
+                    case "$numberDouble":
+                        // NaN 和 Infinity 无法用普通 JSON 表示，保持包装 // EN: NaN and Infinity cannot be plain JSON, keep the wrapper
+                        if let d = Double(text), d.isFinite { return d }
+                    default:
+                        break
+                    }
+                }
+                return dict.mapValues(relax)
+            }
+            if let array = value as? [Any] {
+                return array.map(relax)
+            }
+            return value
+        }
+        let object = try JSONSerialization.jsonObject(with: data)
+        return try JSONSerialization.data(withJSONObject: relax(object))
+    }
+
+    /// 运行器只能表示普通 JSON 值，展开数值包装后仍保留的 Extended JSON 类型包装
+    /// EN: Extended JSON type wrappers that are left after unwrapping numbers; the runner can only represent plain JSON values
+    private static let unsupportedWrappers: Set<String> = [
+        "$oid", "$date", "$numberDecimal", "$numberDouble", "$binary",
+        "$regularExpression", "$timestamp", "$minKey", "$maxKey"
+    ]
+
+    /// 找出使用了无法表示的 Extended JSON 类型的测试用例
+    /// EN: Find the test cases that use an Extended JSON type which cannot be represented
+    /// - Parameter data: 展开数值包装后的 JSON 数据 / JSON data with number wrappers unwrapped
+    /// - Returns: 测试名称到第一个无法表示的类型包装 / Test name to the first wrapper that cannot be represented
+    private static func unsupportedTypes(_ data: Data) throws -> [String: String] {
+        func find(_ value: Any) -> String? {
+            if let dict = value as? [String: Any] {
+                if let key = dict.keys.first(where: { Self.unsupportedWrappers.contains($0) }) {
+                    return key
+                }
+                return dict.keys.sorted().lazy.compactMap { find(dict[$0]!) }.first
+            }
+            if let array = value as? [Any] {
+                return array.lazy.compactMap(find).first
+            }
+            return nil
+        }
+        let object = try JSONSerialization.jsonObject(with: data)
+        let tests = (object as? [String: Any])?["tests"] as? [[String: Any]] ?? []
+        var unsupported: [String: String] = [:]
+        for test in tests {
+            if let name = test["name"] as? String, let wrapper = find(test) {
+                unsupported[name] = wrapper
+            }
+        }
+        return unsupported
+    }
+
     /// 解析相对路径为绝对路径
     /// EN: Resolve relative path to absolute path
     /// - Parameters:
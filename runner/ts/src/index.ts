@@ -3,6 +3,7 @@
 import { Command } from 'commander';
 import * as fs from 'fs';
 import * as path from 'path';
+import { BSON } from 'mongodb';
 import { APIRunner } from './apiRunner';
 import { WireRunner } from './wireRunner';
 import { TestSuite, TestResult, ResultsFile } from './types';
@@ -50,9 +51,10 @@ async function main(): Promise<void> {
     // // EN: Deleted existing database: {path}
   }
 
-  // 加载测试用例 // EN: Load test cases
+  // 加载测试用例（Extended JSON v2，数值解析为原生 number）
+  // // EN: Load test cases (Extended JSON v2, numbers are parsed as native numbers)
   const suiteData = fs.readFileSync(testcasesPath, 'utf-8');
-  const suite: TestSuite = JSON.parse(suiteData);
+  const suite: TestSuite = BSON.EJSON.parse(suiteData, { relaxed: true }) as TestSuite;
   console.log(`加载了 ${suite.tests.length} 个测试用例`);
   // // EN: Loaded {count} test cases
 
@@ -14,7 +14,7 @@ func GenerateAggregateTests() []TestCase {
 		{Operation: "insert", Data: doc("_id", "agg_005", "name", "Eve", "age", 22, "dept", "Engineering")},
 	}
 
-	return []TestCase{
+	tests := []TestCase{
 		{
 			Name:        "agg_match_simple",
 			Category:    "aggregate",
@@ -77,5 +77,246 @@ func GenerateAggregateTests() []TestCase {
 			},
 			Expected: Expected{Count: intPtr(3)},
 		},
+		{
+			Name:        "agg_sort_compound",
+			Category:    "aggregate",
+			Operation:   "$sort",
+			Collection:  "agg_compound_test",
+			Description: "$sort 多键排序保持键顺序", // EN: $sort with multiple keys keeps key order
+			Setup: []SetupStep{
+				{Operation: "drop"},
+				{Operation: "insertMany", Data: []any{
+					doc("_id", "aggc_001", "dept", "Sales", "age", 30),
+					doc("_id", "aggc_002", "dept", "Engineering", "age", 25),
+					doc("_id", "aggc_003", "dept", "Sales", "age", 35),
+					doc("_id", "aggc_004", "dept", "Engineering", "age", 28),
+				}},
+			},
+			Action: TestAction{
+				Method: "aggregate",
+				Options: doc("pipeline", []any{
+					doc("$sort", doc("dept", 1, "age", -1)),
+					doc("$project", doc("_id", 1)),
+				}),
+			},
+			Expected: Expected{
+				Count: intPtr(4),
+				Documents: []any{
+					doc("_id", "aggc_004"),
+					doc("_id", "aggc_002"),
+					doc("_id", "aggc_003"),
+					doc("_id", "aggc_001"),
+				},
+			},
+			Comparison: &Comparison{Ordered: true},
+			Teardown:   []SetupStep{{Operation: "drop"}},
+		},
+	}
+	return append(tests, generateAggregateStageTests()...)
+}
+
+// aggStageDocs 聚合阶段测试使用的订单数据，tags 覆盖普通数组、空数组、缺失和 null
+// EN: aggStageDocs is the order data used by the stage tests; tags covers a regular array, an empty array, a missing field and null.
+func aggStageDocs() []any {
+	return []any{
+		doc("_id", "o1", "item", "apple", "qty", 5, "price", 2, "tags", []any{"fruit", "red"}),
+		doc("_id", "o2", "item", "banana", "qty", 10, "price", 1, "tags", []any{"fruit"}),
+		doc("_id", "o3", "item", "carrot", "qty", 3, "price", 4, "tags", []any{}),
+		doc("_id", "o4", "item", "donut", "qty", 12, "price", 3),
+		doc("_id", "o5", "item", "apple", "qty", 7, "price", 2, "tags", nil),
+	}
+}
+
+// generateAggregateStageTests 生成聚合阶段测试；管道以确定的顺序输出，结果按顺序比较
+// EN: generateAggregateStageTests generates aggregation stage tests; pipelines emit a deterministic order and results are compared in order.
+func generateAggregateStageTests() []TestCase {
+	orders := aggStageDocs()
+
+	lookup := aggStageTest("agg_lookup", "$lookup", "$lookup 关联另一个集合，无匹配时为空数组", []any{ // EN: $lookup joins another collection, an empty array when nothing matches
+		doc("$match", doc("_id", doc("$in", []any{"o1", "o3"}))),
+		doc("$lookup", doc("from", "agg_stage_inventory", "localField", "item", "foreignField", "sku", "as", "inv")),
+		doc("$project", doc("item", 1, "inv", 1)),
+		doc("$sort", doc("_id", 1)),
+	}, []any{
+		doc("_id", "o1", "item", "apple", "inv", []any{doc("_id", "i1", "sku", "apple", "stock", 50)}),
+		doc("_id", "o3", "item", "carrot", "inv", []any{}),
+	})
+	lookup.Setup = append(lookup.Setup,
+		SetupStep{Operation: "drop", Collection: "agg_stage_inventory"},
+		SetupStep{Operation: "insertMany", Collection: "agg_stage_inventory", Data: []any{
+			doc("_id", "i1", "sku", "apple", "stock", 50),
+			doc("_id", "i2", "sku", "banana", "stock", 0),
+		}},
+	)
+	lookup.Teardown = append(lookup.Teardown, SetupStep{Operation: "drop", Collection: "agg_stage_inventory"})
+
+	sample := aggStageTest("agg_sample", "$sample", "$sample 只断言返回数量", []any{ // EN: $sample only asserts the number of results
+		doc("$sample", doc("size", 2)),
+	}, nil)
+	sample.Expected = Expected{Count: intPtr(2)}
+
+	out := aggStageTest("agg_out", "$out", "$out 将结果写入目标集合", []any{ // EN: $out writes the results into a target collection
+		doc("$match", doc("price", doc("$lte", 2))),
+		doc("$out", "agg_stage_out"),
+	}, nil)
+	out.Expected = Expected{Count: intPtr(0)}
+	out.Verify = &Verify{
+		Collection: "agg_stage_out",
+		Filter:     doc(),
+		Options:    doc("sort", doc("_id", 1)),
+		Documents:  []any{orders[0], orders[1], orders[4]},
+	}
+	out.Setup = append(out.Setup, SetupStep{Operation: "drop", Collection: "agg_stage_out"})
+	out.Teardown = append(out.Teardown, SetupStep{Operation: "drop", Collection: "agg_stage_out"})
+
+	merge := aggStageTest("agg_merge", "$merge", "$merge 合并到已有文档并插入新文档", []any{ // EN: $merge merges into existing documents and inserts new ones
+		doc("$match", doc("_id", doc("$in", []any{"o1", "o2"}))),
+		doc("$project", doc("qty", 1)),
+		doc("$merge", doc("into", "agg_stage_merge", "on", "_id", "whenMatched", "merge", "whenNotMatched", "insert")),
+	}, nil)
+	merge.Expected = Expected{Count: intPtr(0)}
+	merge.Verify = &Verify{
+		Collection: "agg_stage_merge",
+		Filter:     doc(),
+		Options:    doc("sort", doc("_id", 1)),
+		Documents: []any{
+			doc("_id", "o1", "note", "old", "qty", 5),
+			doc("_id", "o2", "qty", 10),
+			doc("_id", "x9", "keep", true),
+		},
+	}
+	merge.Setup = append(merge.Setup,
+		SetupStep{Operation: "drop", Collection: "agg_stage_merge"},
+		SetupStep{Operation: "insertMany", Collection: "agg_stage_merge", Data: []any{
+			doc("_id", "o1", "note", "old"),
+			doc("_id", "x9", "keep", true),
+		}},
+	)
+	merge.Teardown = append(merge.Teardown, SetupStep{Operation: "drop", Collection: "agg_stage_merge"})
+
+	return []TestCase{
+		aggStageTest("agg_skip", "$skip", "$sort 之后 $skip", []any{ // EN: $skip after $sort
+			doc("$sort", doc("_id", 1)),
+			doc("$skip", 3),
+		}, []any{orders[3], orders[4]}),
+		aggStageTest("agg_count", "$count", "$count 统计匹配的文档数", []any{ // EN: $count counts the matching documents
+			doc("$match", doc("qty", doc("$gte", 5))),
+			doc("$count", "n"),
+		}, []any{doc("n", 4)}),
+		aggStageTest("agg_unwind", "$unwind", "$unwind 跳过空数组、缺失字段和 null", []any{ // EN: $unwind skips empty arrays, missing fields and null
+			doc("$unwind", "$tags"),
+			doc("$project", doc("tags", 1)),
+			doc("$sort", doc("_id", 1, "tags", 1)),
+		}, []any{
+			doc("_id", "o1", "tags", "fruit"),
+			doc("_id", "o1", "tags", "red"),
+			doc("_id", "o2", "tags", "fruit"),
+		}),
+		aggStageTest("agg_unwind_preserve", "$unwind", "$unwind preserveNullAndEmptyArrays 保留空数组、缺失字段和 null", []any{ // EN: $unwind preserveNullAndEmptyArrays keeps empty arrays, missing fields and null
+			doc("$unwind", doc("path", "$tags", "preserveNullAndEmptyArrays", true)),
+			doc("$project", doc("tags", 1)),
+			doc("$sort", doc("_id", 1, "tags", 1)),
+		}, []any{
+			doc("_id", "o1", "tags", "fruit"),
+			doc("_id", "o1", "tags", "red"),
+			doc("_id", "o2", "tags", "fruit"),
+			doc("_id", "o3"),
+			doc("_id", "o4"),
+			doc("_id", "o5", "tags", nil),
+		}),
+		aggStageTest("agg_unwind_index", "$unwind", "$unwind includeArrayIndex 记录元素下标", []any{ // EN: $unwind includeArrayIndex records the element index
+			doc("$unwind", doc("path", "$tags", "includeArrayIndex", "idx")),
+			doc("$project", doc("tags", 1, "idx", 1)),
+			doc("$sort", doc("_id", 1, "idx", 1)),
+		}, []any{
+			doc("_id", "o1", "tags", "fruit", "idx", int64(0)),
+			doc("_id", "o1", "tags", "red", "idx", int64(1)),
+			doc("_id", "o2", "tags", "fruit", "idx", int64(0)),
+		}),
+		lookup,
+		aggStageTest("agg_add_fields", "$addFields", "$addFields 在末尾添加计算字段", []any{ // EN: $addFields appends a computed field
+			doc("$match", doc("_id", "o1")),
+			doc("$addFields", doc("total", doc("$multiply", []any{"$qty", "$price"}))),
+		}, []any{doc("_id", "o1", "item", "apple", "qty", 5, "price", 2, "tags", []any{"fruit", "red"}, "total", 10)}),
+		aggStageTest("agg_set", "$set", "$set 是 $addFields 的别名，可覆盖已有字段", []any{ // EN: $set is an alias of $addFields and can overwrite existing fields
+			doc("$match", doc("_id", "o2")),
+			doc("$set", doc("item", doc("$toUpper", "$item"))),
+		}, []any{doc("_id", "o2", "item", "BANANA", "qty", 10, "price", 1, "tags", []any{"fruit"})}),
+		aggStageTest("agg_unset", "$unset", "$unset 删除多个字段", []any{ // EN: $unset removes several fields
+			doc("$match", doc("_id", "o1")),
+			doc("$unset", []any{"tags", "price"}),
+		}, []any{doc("_id", "o1", "item", "apple", "qty", 5)}),
+		aggStageTest("agg_replace_root", "$replaceRoot", "$replaceRoot 用新文档替换根文档", []any{ // EN: $replaceRoot replaces the root with a new document
+			doc("$match", doc("_id", "o1")),
+			doc("$replaceRoot", doc("newRoot", doc("name", "$item", "amount", "$qty"))),
+		}, []any{doc("name", "apple", "amount", 5)}),
+		aggStageTest("agg_facet", "$facet", "$facet 在同一输入上运行多个子管道", []any{ // EN: $facet runs several sub-pipelines over the same input
+			doc("$facet", doc(
+				"cheap", []any{doc("$match", doc("price", doc("$lte", 2))), doc("$count", "n")},
+				"byItem", []any{doc("$group", doc("_id", "$item", "n", doc("$sum", 1))), doc("$sort", doc("_id", 1))},
+			)),
+		}, []any{doc(
+			"cheap", []any{doc("n", 3)},
+			"byItem", []any{
+				doc("_id", "apple", "n", 2),
+				doc("_id", "banana", "n", 1),
+				doc("_id", "carrot", "n", 1),
+				doc("_id", "donut", "n", 1),
+			},
+		)}),
+		aggStageTest("agg_bucket", "$bucket", "$bucket 按边界分桶", []any{ // EN: $bucket groups by boundaries
+			doc("$bucket", doc(
+				"groupBy", "$qty",
+				"boundaries", []any{0, 5, 10, 20},
+				"default", "other",
+				"output", doc("count", doc("$sum", 1)),
+			)),
+		}, []any{
+			doc("_id", 0, "count", 1),
+			doc("_id", 5, "count", 2),
+			doc("_id", 10, "count", 2),
+		}),
+		aggStageTest("agg_bucket_auto", "$bucketAuto", "$bucketAuto 自动划分均匀的桶", []any{ // EN: $bucketAuto splits into even buckets automatically
+			doc("$match", doc("_id", doc("$in", []any{"o1", "o2", "o3", "o4"}))),
+			doc("$bucketAuto", doc("groupBy", "$qty", "buckets", 2)),
+		}, []any{
+			doc("_id", doc("min", 3, "max", 10), "count", 2),
+			doc("_id", doc("min", 10, "max", 12), "count", 2),
+		}),
+		aggStageTest("agg_sort_by_count", "$sortByCount", "$sortByCount 按出现次数分组排序", []any{ // EN: $sortByCount groups and sorts by number of occurrences
+			doc("$sortByCount", "$item"),
+			doc("$sort", doc("count", -1, "_id", 1)),
+		}, []any{
+			doc("_id", "apple", "count", 2),
+			doc("_id", "banana", "count", 1),
+			doc("_id", "carrot", "count", 1),
+			doc("_id", "donut", "count", 1),
+		}),
+		sample,
+		out,
+		merge,
+	}
+}
+
+// aggStageTest 构造在 agg_stage 订单数据上运行管道并按顺序比较结果的测试
+// EN: aggStageTest builds a test that runs a pipeline over the agg_stage order data and compares the results in order.
+func aggStageTest(name, operation, description string, pipeline []any, expected []any) TestCase {
+	return TestCase{
+		Name:        name,
+		Category:    "aggregate",
+		Operation:   operation,
+		Collection:  "agg_stage",
+		Description: description,
+		Setup: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "insertMany", Data: aggStageDocs()},
+		},
+		Action: TestAction{
+			Method:  "aggregate",
+			Options: doc("pipeline", pipeline),
+		},
+		Expected:   Expected{Count: intPtr(int64(len(expected))), Documents: expected},
+		Comparison: &Comparison{Ordered: true},
+		Teardown:   []SetupStep{{Operation: "drop"}},
 	}
 }
@@ -0,0 +1,167 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bsonTypeCase 单个 BSON 类型的测试数据
+// EN: bsonTypeCase holds the test data for a single BSON type.
+type bsonTypeCase struct {
+	name        string // 测试名称后缀 // EN: Test name suffix
+	description string // 描述 // EN: Description
+	value       any    // 写入并读回的值 // EN: Value written and read back
+	matchable   bool   // 是否可以按值做等值查询 // EN: Whether the value can be used in an equality filter
+}
+
+// GenerateBSONTypeTests 生成 BSON 类型覆盖测试
+// 每个类型都会写入后按 _id 读回并严格比较，可等值匹配的类型还会按值查询
+// EN: GenerateBSONTypeTests generates BSON type coverage tests.
+// EN: Every type is written, read back by _id and compared strictly; types that support equality are also queried by value.
+func GenerateBSONTypeTests() []TestCase {
+	var tests []TestCase
+	for _, c := range bsonTypeCases() {
+		tests = append(tests, bsonRoundTripTest(c))
+		if c.matchable {
+			tests = append(tests, bsonMatchTest(c))
+		}
+	}
+	return tests
+}
+
+// bsonTypeCases 返回所有需要覆盖的 BSON 类型
+// EN: bsonTypeCases returns every BSON type to cover.
+func bsonTypeCases() []bsonTypeCase {
+	objectID := mustObjectID("65a1b2c3d4e5f60718293a4b")
+	uuid := []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0x4d, 0xef, 0x81, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+
+	return []bsonTypeCase{
+		{"objectid", "ObjectId", objectID, true},
+		{"date", "UTC 日期时间（毫秒精度）", dateValue(2024, time.January, 15, 10, 30, 0, 123), true},    // EN: UTC datetime (millisecond precision)
+		{"date_pre_epoch", "1970 年之前的日期", dateValue(1969, time.July, 20, 20, 17, 40, 0), true}, // EN: Date before 1970
+		{"decimal128", "Decimal128", mustDecimal("12345.6789"), true},
+		{"decimal128_negative", "负的 Decimal128 小数", mustDecimal("-0.001"), true},                              // EN: Negative Decimal128 fraction
+		{"binary_generic", "Binary 通用子类型 0x00", primitive.Binary{Subtype: 0x00, Data: []byte("hello")}, true}, // EN: Binary generic subtype 0x00
+		{"binary_uuid", "Binary UUID 子类型 0x04", primitive.Binary{Subtype: 0x04, Data: uuid}, true},            // EN: Binary UUID subtype 0x04
+		{"binary_md5", "Binary MD5 子类型 0x05", primitive.Binary{Subtype: 0x05, Data: uuid}, true},              // EN: Binary MD5 subtype 0x05
+		{"binary_user", "Binary 用户自定义子类型 0x80", primitive.Binary{Subtype: 0x80, Data: []byte{0, 1, 2}}, true}, // EN: Binary user-defined subtype 0x80
+		{"regex", "正则表达式（作为值存储）", primitive.Regex{Pattern: "^abc", Options: "i"}, false},                      // EN: Regular expression (stored as a value)
+		{"javascript", "JavaScript 代码", primitive.JavaScript("function() { return 1; }"), true},               // EN: JavaScript code
+		{"timestamp", "Timestamp", primitive.Timestamp{T: 1700000000, I: 1}, true},
+		{"minkey", "MinKey", primitive.MinKey{}, true},
+		{"maxkey", "MaxKey", primitive.MaxKey{}, true},
+		{"nested", "多层嵌套的混合类型", nestedValue(objectID, uuid), false}, // EN: Deeply nested mixed types
+	}
+}
+
+// bsonRoundTripTest 写入值后按 _id 读回，严格比较类型和字段顺序
+// EN: bsonRoundTripTest writes the value, reads it back by _id and compares types and field order strictly.
+func bsonRoundTripTest(c bsonTypeCase) TestCase {
+	id := "bson_" + c.name
+	return TestCase{
+		Name:        "bson_roundtrip_" + c.name,
+		Category:    "bson_types",
+		Operation:   "roundtrip",
+		Collection:  "bson_types",
+		Description: c.description + " 写入后读回", // EN: written and read back
+		Setup: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "insert", Data: doc("_id", id, "value", c.value)},
+		},
+		Action: TestAction{
+			Method: "find",
+			Filter: doc("_id", id),
+		},
+		Expected: Expected{
+			Count:     intPtr(1),
+			Documents: []any{doc("_id", id, "value", c.value)},
+		},
+		Comparison: &Comparison{Mode: "strict"},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
+}
+
+// bsonMatchTest 按值做等值查询，只应匹配同类型的文档
+// EN: bsonMatchTest queries by value equality and should only match the document of the same type.
+func bsonMatchTest(c bsonTypeCase) TestCase {
+	id := "bson_" + c.name
+	return TestCase{
+		Name:        "bson_match_" + c.name,
+		Category:    "bson_types",
+		Operation:   "match",
+		Collection:  "bson_types",
+		Description: c.description + " 等值查询", // EN: equality query
+		Setup: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "insertMany", Data: []any{
+				doc("_id", id, "value", c.value),
+				doc("_id", "bson_string", "value", "65a1b2c3d4e5f60718293a4b"),
+				doc("_id", "bson_null", "value", nil),
+			}},
+		},
+		Action: TestAction{
+			Method: "find",
+			Filter: doc("value", c.value),
+		},
+		Expected: Expected{
+			Count:     intPtr(1),
+			Documents: []any{doc("_id", id, "value", c.value)},
+		},
+		Comparison: &Comparison{Mode: "strict"},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
+}
+
+// nestedValue 构造多层嵌套、混合类型的值
+// EN: nestedValue builds a deeply nested value of mixed types.
+func nestedValue(objectID primitive.ObjectID, uuid []byte) any {
+	return doc(
+		"owner", objectID,
+		"created", dateValue(2024, time.March, 1, 0, 0, 0, 0),
+		"items", []any{
+			doc("sku", "A-1", "price", mustDecimal("19.99"), "qty", int32(2)),
+			doc("sku", "B-2", "price", mustDecimal("5.50"), "qty", int64(10), "tags", []any{"x", nil, true}),
+		},
+		"meta", doc(
+			"level1", doc(
+				"level2", doc(
+					"level3", doc(
+						"id", primitive.Binary{Subtype: 0x04, Data: uuid},
+						"ts", primitive.Timestamp{T: 1700000000, I: 2},
+						"ratio", 0.25,
+						"bounds", []any{primitive.MinKey{}, primitive.MaxKey{}},
+					),
+				),
+			),
+		),
+	)
+}
+
+// mustObjectID 辅助函数：解析十六进制 ObjectId
+// EN: mustObjectID is a helper function to parse a hex ObjectId.
+func mustObjectID(hex string) primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// mustDecimal 辅助函数：解析 Decimal128
+// EN: mustDecimal is a helper function to parse a Decimal128.
+func mustDecimal(s string) primitive.Decimal128 {
+	d, err := primitive.ParseDecimal128(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// dateValue 辅助函数：构造毫秒精度的 UTC 日期时间
+// EN: dateValue is a helper function to build a UTC datetime with millisecond precision.
+func dateValue(year int, month time.Month, day, hour, min, sec, msec int) primitive.DateTime {
+	return primitive.NewDateTimeFromTime(time.Date(year, month, day, hour, min, sec, msec*int(time.Millisecond), time.UTC))
+}
@@ -28,6 +28,12 @@ func GenerateCRUDTests() []TestCase {
 	// Distinct 测试 // EN: Distinct tests
 	tests = append(tests, generateDistinctTests()...)
 
+	// findOneAnd*、countDocuments、bulkWrite 等方法测试 // EN: findOneAnd*, countDocuments, bulkWrite and other method tests
+	tests = append(tests, generateMethodTests()...)
+
+	// 状态重置测试 // EN: State reset tests
+	tests = append(tests, generateResetTests()...)
+
 	return tests
 }
 
@@ -177,6 +183,32 @@ func generateFindTests() []TestCase {
 			},
 			Expected: Expected{Count: intPtr(2)},
 		},
+		{
+			Name:        "find_base_types_strict",
+			Category:    "crud",
+			Operation:   "find",
+			Collection:  "base",
+			Description: "严格校验基础集合的 BSON 类型", // EN: Strictly verify BSON types in the base collection
+			Action: TestAction{
+				Method:  "find",
+				Filter:  doc(),
+				Options: doc("sort", doc("_id", 1)),
+			},
+			Expected: Expected{
+				Count: intPtr(8),
+				Documents: []any{
+					doc("_id", "base_001", "type", "string", "value", "hello world"),
+					doc("_id", "base_002", "type", "int32", "value", int32(42)),
+					doc("_id", "base_003", "type", "int64", "value", int64(9007199254740993)),
+					doc("_id", "base_004", "type", "double", "value", 3.14159),
+					doc("_id", "base_005", "type", "bool", "value", true),
+					doc("_id", "base_006", "type", "null", "value", nil),
+					doc("_id", "base_007", "type", "array", "value", []any{int32(1), int32(2), int32(3)}),
+					doc("_id", "base_008", "type", "document", "value", doc("nested", "value")),
+				},
+			},
+			Comparison: &Comparison{Mode: "strict", Ordered: true},
+		},
 		{
 			Name:        "find_with_skip",
 			Category:    "crud",
@@ -193,6 +225,38 @@ func generateFindTests() []TestCase {
 			},
 			Expected: Expected{Count: intPtr(3)},
 		},
+		{
+			Name:        "find_compound_sort",
+			Category:    "crud",
+			Operation:   "find",
+			Collection:  "sort_test",
+			Description: "多键排序，键顺序决定结果顺序", // EN: Multi-key sort where key order determines the result order
+			Setup: []SetupStep{
+				{Operation: "drop"},
+				{Operation: "insertMany", Data: []any{
+					doc("_id", "sort_001", "category", "a", "price", 10),
+					doc("_id", "sort_002", "category", "b", "price", 5),
+					doc("_id", "sort_003", "category", "a", "price", 20),
+					doc("_id", "sort_004", "category", "b", "price", 15),
+				}},
+			},
+			Action: TestAction{
+				Method:  "find",
+				Filter:  doc(),
+				Options: doc("sort", doc("category", 1, "price", -1)),
+			},
+			Expected: Expected{
+				Count: intPtr(4),
+				Documents: []any{
+					doc("_id", "sort_003", "category", "a", "price", 20),
+					doc("_id", "sort_001", "category", "a", "price", 10),
+					doc("_id", "sort_004", "category", "b", "price", 15),
+					doc("_id", "sort_002", "category", "b", "price", 5),
+				},
+			},
+			Comparison: &Comparison{Ordered: true},
+			Teardown:   []SetupStep{{Operation: "drop"}},
+		},
 	}
 }
 
@@ -215,6 +279,10 @@ func generateUpdateTests() []TestCase {
 				Update: doc("$set", doc("age", 41)),
 			},
 			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", "update_001"),
+				Documents: []any{doc("_id", "update_001", "name", "Frank", "age", 41)},
+			},
 		},
 		{
 			Name:        "update_with_upsert",
@@ -229,6 +297,10 @@ func generateUpdateTests() []TestCase {
 				Options: doc("upsert", true),
 			},
 			Expected: Expected{MatchedCount: intPtr(0), ModifiedCount: intPtr(0)},
+			Verify: &Verify{
+				Filter:    doc("_id", "upsert_001"),
+				Documents: []any{doc("_id", "upsert_001", "name", "George", "created", true)},
+			},
 		},
 		{
 			Name:        "update_multiple_docs",
@@ -247,6 +319,59 @@ func generateUpdateTests() []TestCase {
 				Update: doc("$set", doc("status", "inactive")),
 			},
 			Expected: Expected{MatchedCount: intPtr(3), ModifiedCount: intPtr(3)},
+			Verify: &Verify{
+				Filter: doc("_id", doc("$in", []any{"multi_001", "multi_002", "multi_003"})),
+				Documents: []any{
+					doc("_id", "multi_001", "status", "inactive"),
+					doc("_id", "multi_002", "status", "inactive"),
+					doc("_id", "multi_003", "status", "inactive"),
+				},
+			},
+		},
+		{
+			Name:        "update_one_multiple_matches",
+			Category:    "crud",
+			Operation:   "update",
+			Collection:  "crud_test",
+			Description: "updateOne 的过滤条件匹配多个文档时只更新一个", // EN: updateOne updates only one document when the filter matches several
+			Setup: []SetupStep{
+				{Operation: "insert", Data: doc("_id", "one_001", "batch", "one_of_many")},
+				{Operation: "insert", Data: doc("_id", "one_002", "batch", "one_of_many")},
+				{Operation: "insert", Data: doc("_id", "one_003", "batch", "one_of_many")},
+			},
+			Action: TestAction{
+				Method: "updateOne",
+				Filter: doc("batch", "one_of_many"),
+				Update: doc("$set", doc("picked", true)),
+			},
+			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("batch", "one_of_many", "picked", true),
+				Options:   doc("projection", doc("_id", 0)),
+				Documents: []any{doc("batch", "one_of_many", "picked", true)},
+			},
+		},
+		{
+			Name:        "update_one_id_in_multiple_matches",
+			Category:    "crud",
+			Operation:   "update",
+			Collection:  "crud_test",
+			Description: "updateOne 按 _id $in 匹配多个文档时只更新一个", // EN: updateOne updates only one document when an _id $in filter matches several
+			Setup: []SetupStep{
+				{Operation: "insert", Data: doc("_id", "one_in_001", "n", 1)},
+				{Operation: "insert", Data: doc("_id", "one_in_002", "n", 1)},
+			},
+			Action: TestAction{
+				Method: "updateOne",
+				Filter: doc("_id", doc("$in", []any{"one_in_001", "one_in_002"})),
+				Update: doc("$inc", doc("n", 1)),
+			},
+			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", doc("$in", []any{"one_in_001", "one_in_002"}), "n", 2),
+				Options:   doc("projection", doc("_id", 0)),
+				Documents: []any{doc("n", 2)},
+			},
 		},
 	}
 }
@@ -269,6 +394,7 @@ func generateDeleteTests() []TestCase {
 				Filter: doc("_id", "delete_001"),
 			},
 			Expected: Expected{DeletedCount: intPtr(1)},
+			Verify:   &Verify{Filter: doc("_id", "delete_001"), Documents: []any{}},
 		},
 		{
 			Name:        "delete_multiple_docs",
@@ -285,6 +411,7 @@ func generateDeleteTests() []TestCase {
 				Filter: doc("toDelete", true),
 			},
 			Expected: Expected{DeletedCount: intPtr(2)},
+			Verify:   &Verify{Filter: doc("toDelete", true), Documents: []any{}},
 		},
 		{
 			Name:        "delete_no_match",
@@ -320,6 +447,28 @@ func generateReplaceTests() []TestCase {
 				Doc:    doc("_id", "replace_001", "new", "value", "replaced", true),
 			},
 			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", "replace_001"),
+				Documents: []any{doc("_id", "replace_001", "new", "value", "replaced", true)},
+			},
+		},
+		{
+			Name:        "replace_with_upsert",
+			Category:    "crud",
+			Operation:   "replace",
+			Collection:  "crud_test",
+			Description: "没有匹配文档时 replaceOne upsert 插入替换文档", // EN: replaceOne with upsert inserts the replacement when nothing matches
+			Action: TestAction{
+				Method:  "replaceOne",
+				Filter:  doc("_id", "replace_upsert_001"),
+				Doc:     doc("name", "Hana", "replaced", true),
+				Options: doc("upsert", true),
+			},
+			Expected: Expected{MatchedCount: intPtr(0), ModifiedCount: intPtr(0), UpsertedID: "replace_upsert_001"},
+			Verify: &Verify{
+				Filter:    doc("_id", "replace_upsert_001"),
+				Documents: []any{doc("_id", "replace_upsert_001", "name", "Hana", "replaced", true)},
+			},
 		},
 	}
 }
@@ -344,6 +493,10 @@ func generateFindAndModifyTests() []TestCase {
 				Options: doc("new", true),
 			},
 			Expected: Expected{Count: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", "fam_001"),
+				Documents: []any{doc("_id", "fam_001", "counter", 1)},
+			},
 		},
 		{
 			Name:        "find_and_modify_delete",
@@ -360,6 +513,7 @@ func generateFindAndModifyTests() []TestCase {
 				Options: doc("remove", true),
 			},
 			Expected: Expected{Count: intPtr(1)},
+			Verify:   &Verify{Filter: doc("_id", "fam_002"), Documents: []any{}},
 		},
 	}
 }
@@ -382,3 +536,43 @@ func generateDistinctTests() []TestCase {
 		},
 	}
 }
+
+// generateResetTests 生成前置/清理步骤测试用例
+// EN: generateResetTests generates test cases for setup and teardown steps.
+func generateResetTests() []TestCase {
+	return []TestCase{
+		{
+			Name:        "setup_reset_collection",
+			Category:    "crud",
+			Operation:   "find",
+			Collection:  "reset_test",
+			Description: "重建集合后查询，清理步骤删除集合", // EN: Query a rebuilt collection, teardown drops it
+			Setup: []SetupStep{
+				{Operation: "drop"},
+				{Operation: "createCollection"},
+				{Operation: "insertMany", Data: []any{
+					doc("_id", "reset_001", "keep", true),
+					doc("_id", "reset_002", "keep", false),
+					doc("_id", "reset_003", "keep", true),
+				}},
+				{Operation: "deleteMany", Data: doc("keep", false)},
+				{Operation: "createIndex", Data: doc("keys", doc("keep", 1), "options", doc("name", "keep_1"))},
+				{Operation: "dropIndex", Data: doc("name", "keep_1")},
+			},
+			Action: TestAction{
+				Method: "find",
+				Filter: doc(),
+			},
+			Expected: Expected{
+				Count: intPtr(2),
+				Documents: []any{
+					doc("_id", "reset_001", "keep", true),
+					doc("_id", "reset_003", "keep", true),
+				},
+			},
+			Teardown: []SetupStep{
+				{Operation: "drop"},
+			},
+		},
+	}
+}
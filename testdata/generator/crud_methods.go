@@ -0,0 +1,154 @@
+// Created by Yanjunhui
+
+package main
+
+// generateMethodTests 生成 findOneAnd*、countDocuments、bulkWrite、drop 和 listCollections 等方法的测试用例，
+// 两种运行器都必须能够执行这些方法
+// EN: generateMethodTests generates test cases for findOneAnd*, countDocuments, bulkWrite, drop, listCollections and similar methods,
+// EN: which both runners must be able to execute.
+func generateMethodTests() []TestCase {
+	byID := doc("sort", doc("_id", 1))
+
+	return []TestCase{
+		methodTest("find_one_and_update_after", "findOneAndUpdate 返回更新后的文档", // EN: findOneAndUpdate returns the updated document
+			TestAction{
+				Method:  "findOneAndUpdate",
+				Filter:  doc("_id", "m1"),
+				Update:  doc("$inc", doc("qty", 5)),
+				Options: doc("returnDocument", "after"),
+			},
+			Expected{Count: intPtr(1), Documents: []any{doc("_id", "m1", "item", "a", "qty", 6)}},
+			&Verify{Filter: doc("_id", "m1"), Documents: []any{doc("_id", "m1", "item", "a", "qty", 6)}}),
+		methodTest("find_one_and_update_before", "findOneAndUpdate 默认返回更新前的文档", // EN: findOneAndUpdate returns the original document by default
+			TestAction{
+				Method: "findOneAndUpdate",
+				Filter: doc("_id", "m2"),
+				Update: doc("$set", doc("item", "b2")),
+			},
+			Expected{Count: intPtr(1), Documents: []any{doc("_id", "m2", "item", "b", "qty", 2)}},
+			&Verify{Filter: doc("_id", "m2"), Documents: []any{doc("_id", "m2", "item", "b2", "qty", 2)}}),
+		methodTest("find_one_and_replace", "findOneAndReplace 替换文档并返回替换后的文档", // EN: findOneAndReplace replaces a document and returns the replacement
+			TestAction{
+				Method:  "findOneAndReplace",
+				Filter:  doc("item", "c"),
+				Doc:     doc("item", "c2", "qty", 30),
+				Options: doc("returnDocument", "after"),
+			},
+			Expected{Count: intPtr(1), Documents: []any{doc("_id", "m3", "item", "c2", "qty", 30)}},
+			&Verify{Filter: doc("_id", "m3"), Documents: []any{doc("_id", "m3", "item", "c2", "qty", 30)}}),
+		methodTest("find_one_and_delete", "findOneAndDelete 删除文档并返回被删除的文档", // EN: findOneAndDelete deletes a document and returns it
+			TestAction{
+				Method: "findOneAndDelete",
+				Filter: doc("_id", "m4"),
+			},
+			Expected{Count: intPtr(1), Documents: []any{doc("_id", "m4", "item", "d", "qty", 4)}},
+			&Verify{Filter: doc("_id", "m4"), Documents: []any{}}),
+		methodTest("find_one_and_update_no_match", "findOneAndUpdate 没有匹配文档时不返回文档", // EN: findOneAndUpdate returns no document when nothing matches
+			TestAction{
+				Method:  "findOneAndUpdate",
+				Filter:  doc("_id", "missing"),
+				Update:  doc("$set", doc("qty", 0)),
+				Options: doc("returnDocument", "after"),
+			},
+			Expected{Count: intPtr(0)},
+			nil),
+		methodTest("count_documents_skip_limit", "countDocuments 支持 skip 和 limit", // EN: countDocuments honours skip and limit
+			TestAction{
+				Method:  "countDocuments",
+				Filter:  doc("qty", doc("$gte", 2)),
+				Options: doc("skip", 1, "limit", 1),
+			},
+			Expected{Count: intPtr(1)},
+			nil),
+		methodTest("count_documents_filter", "countDocuments 统计匹配的文档", // EN: countDocuments counts the matching documents
+			TestAction{
+				Method: "countDocuments",
+				Filter: doc("qty", doc("$gte", 2)),
+			},
+			Expected{Count: intPtr(3)},
+			nil),
+		methodTest("estimated_document_count", "estimatedDocumentCount 返回集合文档总数", // EN: estimatedDocumentCount returns the number of documents in the collection
+			TestAction{Method: "estimatedDocumentCount"},
+			Expected{Count: intPtr(4)},
+			nil),
+		methodTest("bulk_write_mixed", "bulkWrite 按顺序执行插入、更新、替换和删除", // EN: bulkWrite runs inserts, updates, replacements and deletes in order
+			TestAction{
+				Method: "bulkWrite",
+				Options: doc("operations", []any{
+					doc("insertOne", doc("document", doc("_id", "m5", "item", "e", "qty", 5))),
+					doc("updateOne", doc("filter", doc("_id", "m1"), "update", doc("$set", doc("qty", 10)))),
+					doc("updateMany", doc("filter", doc("qty", doc("$gte", 4)), "update", doc("$set", doc("big", true)))),
+					doc("replaceOne", doc("filter", doc("_id", "m2"), "replacement", doc("item", "b", "qty", 0))),
+					doc("deleteOne", doc("filter", doc("_id", "m3"))),
+				}),
+			},
+			Expected{Count: intPtr(1), MatchedCount: intPtr(5), ModifiedCount: intPtr(5), DeletedCount: intPtr(1)},
+			&Verify{Filter: doc(), Options: byID, Documents: []any{
+				doc("_id", "m1", "item", "a", "qty", 10, "big", true),
+				doc("_id", "m2", "item", "b", "qty", 0),
+				doc("_id", "m4", "item", "d", "qty", 4, "big", true),
+				doc("_id", "m5", "item", "e", "qty", 5, "big", true),
+			}}),
+		methodTest("bulk_write_upsert", "bulkWrite 中的 upsert 返回插入文档的 _id", // EN: An upsert inside bulkWrite reports the _id of the inserted document
+			TestAction{
+				Method: "bulkWrite",
+				Options: doc("operations", []any{
+					doc("updateOne", doc("filter", doc("_id", "m9"), "update", doc("$set", doc("qty", 9)), "upsert", true)),
+					doc("deleteMany", doc("filter", doc("qty", doc("$lt", 3)))),
+				}),
+			},
+			Expected{MatchedCount: intPtr(0), ModifiedCount: intPtr(0), DeletedCount: intPtr(2), UpsertedID: "m9"},
+			&Verify{Filter: doc(), Options: byID, Documents: []any{
+				doc("_id", "m3", "item", "c", "qty", 3),
+				doc("_id", "m4", "item", "d", "qty", 4),
+				doc("_id", "m9", "qty", 9),
+			}}),
+		methodTest("drop_collection", "drop 删除集合及其中的文档", // EN: drop removes the collection and its documents
+			TestAction{Method: "drop"},
+			Expected{},
+			&Verify{Filter: doc(), Documents: []any{}}),
+		methodTest("list_collections", "listCollections 按名称过滤并返回集合", // EN: listCollections filters collections by name and returns them
+			TestAction{
+				Method: "listCollections",
+				Filter: doc("name", doc("$in", []any{"crud_methods", "crud_methods_aux", "crud_methods_missing"})),
+			},
+			Expected{Count: intPtr(2), Documents: []any{
+				doc("name", "crud_methods"),
+				doc("name", "crud_methods_aux"),
+			}},
+			nil),
+	}
+}
+
+// methodTest 构造在 crud_methods 集合上执行的测试：前置步骤重建集合并插入 m1–m4，
+// 同时创建 listCollections 用到的 crud_methods_aux 集合
+// EN: methodTest builds a test on the crud_methods collection: setup rebuilds it with m1–m4
+// EN: and also creates the crud_methods_aux collection used by listCollections.
+func methodTest(name, description string, action TestAction, expected Expected, verify *Verify) TestCase {
+	return TestCase{
+		Name:        name,
+		Category:    "crud",
+		Operation:   action.Method,
+		Collection:  "crud_methods",
+		Description: description,
+		Setup: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "insertMany", Data: []any{
+				doc("_id", "m1", "item", "a", "qty", 1),
+				doc("_id", "m2", "item", "b", "qty", 2),
+				doc("_id", "m3", "item", "c", "qty", 3),
+				doc("_id", "m4", "item", "d", "qty", 4),
+			}},
+			{Operation: "drop", Collection: "crud_methods_aux"},
+			{Operation: "createCollection", Collection: "crud_methods_aux"},
+		},
+		Action:     action,
+		Expected:   expected,
+		Comparison: &Comparison{Ordered: true},
+		Verify:     verify,
+		Teardown: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "drop", Collection: "crud_methods_aux"},
+		},
+	}
+}
@@ -0,0 +1,365 @@
+// Created by Yanjunhui
+
+package main
+
+import "time"
+
+// exprCase 表达式矩阵中的单个用例
+// EN: exprCase is a single case of the expression matrix.
+type exprCase struct {
+	name      string // 测试名称后缀 // EN: Test name suffix
+	operation string // 被测操作符 // EN: Operator under test
+	expr      any    // 表达式 // EN: Expression
+	want      any    // 预期结果 // EN: Expected result
+}
+
+// GenerateExpressionTests 生成聚合表达式操作符和 $group 累加器测试
+// 所有测试使用严格比较，结果的数值类型也会被检查
+// EN: GenerateExpressionTests generates aggregation expression operator and $group accumulator tests.
+// EN: Every test uses strict comparison, so numeric result types are checked as well.
+func GenerateExpressionTests() []TestCase {
+	var tests []TestCase
+	for _, c := range expressionCases() {
+		tests = append(tests, exprTest(c))
+	}
+	return append(tests, generateAccumulatorTests()...)
+}
+
+// exprDoc 表达式测试使用的文档
+// EN: exprDoc is the document used by the expression tests.
+func exprDoc() any {
+	return doc(
+		"_id", "e1",
+		"a", 10,
+		"b", 3,
+		"d", 2.5,
+		"l", int64(7),
+		"s", "Hello World",
+		"t", "  trim me  ",
+		"arr", []any{1, 2, 3, 4},
+		"arr2", []any{3, 4, 5},
+		"dt", dateValue(2024, time.March, 15, 10, 30, 45, 123),
+		"n", nil,
+		"num", "42",
+	)
+}
+
+// expressionCases 返回按类别分组的表达式用例
+// EN: expressionCases returns the expression cases grouped by kind.
+func expressionCases() []exprCase {
+	var cases []exprCase
+	cases = append(cases, arithmeticCases()...)
+	cases = append(cases, stringCases()...)
+	cases = append(cases, dateCases()...)
+	cases = append(cases, arrayCases()...)
+	cases = append(cases, conditionalCases()...)
+	cases = append(cases, comparisonCases()...)
+	cases = append(cases, setCases()...)
+	cases = append(cases, conversionCases()...)
+	return cases
+}
+
+// arithmeticCases 算术表达式 // EN: arithmeticCases returns arithmetic expressions.
+func arithmeticCases() []exprCase {
+	return []exprCase{
+		{"add_int", "$add", doc("$add", []any{"$a", "$b"}), 13},
+		{"add_double", "$add", doc("$add", []any{"$a", "$d"}), 12.5},
+		{"add_date", "$add", doc("$add", []any{"$dt", 1000}), dateValue(2024, time.March, 15, 10, 30, 46, 123)},
+		{"subtract", "$subtract", doc("$subtract", []any{"$a", "$b"}), 7},
+		{"subtract_dates", "$subtract", doc("$subtract", []any{"$dt", dateValue(2024, time.March, 15, 10, 30, 44, 123)}), int64(1000)},
+		{"multiply_long", "$multiply", doc("$multiply", []any{"$a", "$l"}), int64(70)},
+		{"divide", "$divide", doc("$divide", []any{"$a", "$b"}), float64(10) / 3},
+		{"divide_exact", "$divide", doc("$divide", []any{"$a", 2}), 5.0},
+		{"mod", "$mod", doc("$mod", []any{"$a", "$b"}), 1},
+		{"abs", "$abs", doc("$abs", doc("$subtract", []any{"$b", "$a"})), 7},
+		{"ceil", "$ceil", doc("$ceil", "$d"), 3.0},
+		{"floor", "$floor", doc("$floor", "$d"), 2.0},
+		{"round", "$round", doc("$round", []any{3.14159, 2}), 3.14},
+		{"trunc", "$trunc", doc("$trunc", "$d"), 2.0},
+		{"pow", "$pow", doc("$pow", []any{"$b", 2}), 9},
+		{"sqrt", "$sqrt", doc("$sqrt", 16), 4.0},
+	}
+}
+
+// stringCases 字符串表达式 // EN: stringCases returns string expressions.
+func stringCases() []exprCase {
+	return []exprCase{
+		{"concat", "$concat", doc("$concat", []any{"$s", "!"}), "Hello World!"},
+		{"substr_cp", "$substrCP", doc("$substrCP", []any{"$s", 0, 5}), "Hello"},
+		{"to_upper", "$toUpper", doc("$toUpper", "$s"), "HELLO WORLD"},
+		{"to_lower", "$toLower", doc("$toLower", "$s"), "hello world"},
+		{"trim", "$trim", doc("$trim", doc("input", "$t")), "trim me"},
+		{"ltrim", "$ltrim", doc("$ltrim", doc("input", "$t")), "trim me  "},
+		{"split", "$split", doc("$split", []any{"$s", " "}), []any{"Hello", "World"}},
+		{"str_len_cp", "$strLenCP", doc("$strLenCP", "$s"), 11},
+		{"index_of_cp", "$indexOfCP", doc("$indexOfCP", []any{"$s", "World"}), 6},
+		{"replace_all", "$replaceAll", doc("$replaceAll", doc("input", "$s", "find", "o", "replacement", "0")), "Hell0 W0rld"},
+		{"regex_match", "$regexMatch", doc("$regexMatch", doc("input", "$s", "regex", "^hello", "options", "i")), true},
+		{"strcasecmp", "$strcasecmp", doc("$strcasecmp", []any{"abc", "ABD"}), -1},
+	}
+}
+
+// dateCases 日期表达式 // EN: dateCases returns date expressions.
+func dateCases() []exprCase {
+	return []exprCase{
+		{"year", "$year", doc("$year", "$dt"), 2024},
+		{"month", "$month", doc("$month", "$dt"), 3},
+		{"day_of_month", "$dayOfMonth", doc("$dayOfMonth", "$dt"), 15},
+		{"day_of_week", "$dayOfWeek", doc("$dayOfWeek", "$dt"), 6},
+		{"day_of_year", "$dayOfYear", doc("$dayOfYear", "$dt"), 75},
+		{"hour", "$hour", doc("$hour", "$dt"), 10},
+		{"minute", "$minute", doc("$minute", "$dt"), 30},
+		{"second", "$second", doc("$second", "$dt"), 45},
+		{"millisecond", "$millisecond", doc("$millisecond", "$dt"), 123},
+		{"date_to_string", "$dateToString", doc("$dateToString", doc("format", "%Y-%m-%d %H:%M", "date", "$dt")), "2024-03-15 10:30"},
+		{"date_from_string", "$dateFromString", doc("$dateFromString", doc("dateString", "2024-01-02T03:04:05Z")), dateValue(2024, time.January, 2, 3, 4, 5, 0)},
+		{"date_add", "$dateAdd", doc("$dateAdd", doc("startDate", "$dt", "unit", "day", "amount", 1)), dateValue(2024, time.March, 16, 10, 30, 45, 123)},
+		{"date_diff", "$dateDiff", doc("$dateDiff", doc("startDate", dateValue(2024, time.March, 1, 0, 0, 0, 0), "endDate", "$dt", "unit", "day")), int64(14)},
+	}
+}
+
+// arrayCases 数组表达式 // EN: arrayCases returns array expressions.
+func arrayCases() []exprCase {
+	return []exprCase{
+		{"size", "$size", doc("$size", "$arr"), 4},
+		{"array_elem_at", "$arrayElemAt", doc("$arrayElemAt", []any{"$arr", -1}), 4},
+		{"slice", "$slice", doc("$slice", []any{"$arr", 1, 2}), []any{2, 3}},
+		{"concat_arrays", "$concatArrays", doc("$concatArrays", []any{"$arr", "$arr2"}), []any{1, 2, 3, 4, 3, 4, 5}},
+		{"filter", "$filter", doc("$filter", doc("input", "$arr", "as", "x", "cond", doc("$gt", []any{"$$x", 2}))), []any{3, 4}},
+		{"map", "$map", doc("$map", doc("input", "$arr", "as", "x", "in", doc("$multiply", []any{"$$x", 10}))), []any{10, 20, 30, 40}},
+		{"reduce", "$reduce", doc("$reduce", doc("input", "$arr", "initialValue", 0, "in", doc("$add", []any{"$$value", "$$this"}))), 10},
+		{"in", "$in", doc("$in", []any{2, "$arr"}), true},
+		{"index_of_array", "$indexOfArray", doc("$indexOfArray", []any{"$arr", 3}), 2},
+		{"reverse_array", "$reverseArray", doc("$reverseArray", "$arr"), []any{4, 3, 2, 1}},
+		{"range", "$range", doc("$range", []any{0, 6, 2}), []any{0, 2, 4}},
+		{"is_array", "$isArray", doc("$isArray", "$arr"), true},
+		{"first", "$first", doc("$first", "$arr"), 1},
+		{"last", "$last", doc("$last", "$arr"), 4},
+		{"zip", "$zip", doc("$zip", doc("inputs", []any{"$arr2", []any{"a", "b", "c"}})), []any{[]any{3, "a"}, []any{4, "b"}, []any{5, "c"}}},
+	}
+}
+
+// conditionalCases 条件表达式 // EN: conditionalCases returns conditional expressions.
+func conditionalCases() []exprCase {
+	return []exprCase{
+		{"cond", "$cond", doc("$cond", doc("if", doc("$gte", []any{"$a", 5}), "then", "big", "else", "small")), "big"},
+		{"cond_array", "$cond", doc("$cond", []any{false, 1, 2}), 2},
+		{"if_null", "$ifNull", doc("$ifNull", []any{"$n", "default"}), "default"},
+		{"if_null_missing", "$ifNull", doc("$ifNull", []any{"$missing", "$s"}), "Hello World"},
+		{"switch", "$switch", doc("$switch", doc(
+			"branches", []any{
+				doc("case", doc("$lt", []any{"$a", 5}), "then", "low"),
+				doc("case", doc("$lt", []any{"$a", 20}), "then", "mid"),
+			},
+			"default", "high",
+		)), "mid"},
+	}
+}
+
+// comparisonCases 比较表达式，跨类型时遵循 BSON 类型顺序
+// EN: comparisonCases returns comparison expressions, which follow the BSON type order across types.
+func comparisonCases() []exprCase {
+	return []exprCase{
+		{"cmp", "$cmp", doc("$cmp", []any{"$a", "$b"}), 1},
+		{"eq_cross_type", "$eq", doc("$eq", []any{"$a", 10.0}), true},
+		{"ne", "$ne", doc("$ne", []any{"$a", "$b"}), true},
+		{"gt_string_number", "$gt", doc("$gt", []any{"$s", 5}), true},
+		{"lte_null_number", "$lte", doc("$lte", []any{"$n", 0}), true},
+		{"lt_long", "$lt", doc("$lt", []any{"$a", "$l"}), false},
+	}
+}
+
+// setCases 集合表达式；集合结果的元素顺序不确定，因此断言其大小
+// EN: setCases returns set expressions; the element order of set results is unspecified, so their size is asserted.
+func setCases() []exprCase {
+	return []exprCase{
+		{"set_union", "$setUnion", doc("$size", doc("$setUnion", []any{"$arr", "$arr2"})), 5},
+		{"set_intersection", "$setIntersection", doc("$size", doc("$setIntersection", []any{"$arr", "$arr2"})), 2},
+		{"set_difference", "$setDifference", doc("$size", doc("$setDifference", []any{"$arr", "$arr2"})), 2},
+		{"set_equals", "$setEquals", doc("$setEquals", []any{[]any{1, 2}, []any{2, 1, 1}}), true},
+		{"set_is_subset", "$setIsSubset", doc("$setIsSubset", []any{[]any{1, 2}, "$arr"}), true},
+		{"any_element_true", "$anyElementTrue", doc("$anyElementTrue", []any{[]any{false, 0, 1}}), true},
+		{"all_elements_true", "$allElementsTrue", doc("$allElementsTrue", []any{[]any{true, 1, "a"}}), true},
+	}
+}
+
+// conversionCases 类型转换表达式 // EN: conversionCases returns type conversion expressions.
+func conversionCases() []exprCase {
+	return []exprCase{
+		{"to_int_string", "$toInt", doc("$toInt", "$num"), 42},
+		{"to_int_double", "$toInt", doc("$toInt", "$d"), 2},
+		{"to_long", "$toLong", doc("$toLong", "$a"), int64(10)},
+		{"to_double", "$toDouble", doc("$toDouble", "$num"), 42.0},
+		{"to_decimal", "$toDecimal", doc("$toDecimal", "$a"), mustDecimal("10")},
+		{"to_string_int", "$toString", doc("$toString", "$a"), "10"},
+		{"to_string_double", "$toString", doc("$toString", "$d"), "2.5"},
+		{"to_bool_zero", "$toBool", doc("$toBool", 0), false},
+		{"to_bool_string", "$toBool", doc("$toBool", "$s"), true},
+		{"to_date", "$toDate", doc("$toDate", int64(0)), dateValue(1970, time.January, 1, 0, 0, 0, 0)},
+		{"to_object_id", "$toObjectId", doc("$toObjectId", "65a1b2c3d4e5f60718293a4b"), mustObjectID("65a1b2c3d4e5f60718293a4b")},
+		{"convert_on_error", "$convert", doc("$convert", doc("input", "abc", "to", "int", "onError", -1)), -1},
+		{"convert_on_null", "$convert", doc("$convert", doc("input", "$n", "to", "int", "onNull", 0)), 0},
+		{"type_int", "$type", doc("$type", "$a"), "int"},
+		{"type_double", "$type", doc("$type", "$d"), "double"},
+		{"type_missing", "$type", doc("$type", "$missing"), "missing"},
+		{"is_number", "$isNumber", doc("$isNumber", "$s"), false},
+	}
+}
+
+// exprTest 构造在 $project 中计算表达式并严格比较结果的测试
+// EN: exprTest builds a test that evaluates the expression in $project and compares the result strictly.
+func exprTest(c exprCase) TestCase {
+	return TestCase{
+		Name:        "expr_" + c.name,
+		Category:    "expression",
+		Operation:   c.operation,
+		Collection:  "expr_test",
+		Description: c.operation + " 表达式", // EN: expression
+		Setup: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "insert", Data: exprDoc()},
+		},
+		Action: TestAction{
+			Method: "aggregate",
+			Options: doc("pipeline", []any{
+				doc("$match", doc("_id", "e1")),
+				doc("$project", doc("_id", 0, "r", c.expr)),
+			}),
+		},
+		Expected: Expected{
+			Count:     intPtr(1),
+			Documents: []any{doc("r", c.want)},
+		},
+		Comparison: &Comparison{Mode: "strict", Ordered: true},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
+}
+
+// groupDocs 累加器测试使用的文档；分组 z 只有 null 值
+// EN: groupDocs are the documents used by the accumulator tests; group z only holds null.
+func groupDocs() []any {
+	return []any{
+		doc("_id", "g1", "cat", "x", "v", 1),
+		doc("_id", "g2", "cat", "x", "v", 3),
+		doc("_id", "g3", "cat", "y", "v", 10),
+		doc("_id", "g4", "cat", "x", "v", 2),
+		doc("_id", "g5", "cat", "y", "v", 20),
+		doc("_id", "g6", "cat", "x", "v", 3),
+		doc("_id", "g7", "cat", "z", "v", nil),
+	}
+}
+
+// generateAccumulatorTests 生成 $group 累加器测试；输入先按 _id 排序，以便 $first、$last 和 $push 的结果确定
+// EN: generateAccumulatorTests generates $group accumulator tests; the input is sorted by _id first so $first, $last and $push are deterministic.
+func generateAccumulatorTests() []TestCase {
+	xy := doc("$match", doc("cat", doc("$in", []any{"x", "y"})))
+
+	return []TestCase{
+		accumulatorTest("sum", "$sum", xy, doc("$sum", "$v"), 9, 30),
+		accumulatorTest("avg", "$avg", xy, doc("$avg", "$v"), 2.25, 15.0),
+		accumulatorTest("min", "$min", xy, doc("$min", "$v"), 1, 10),
+		accumulatorTest("max", "$max", xy, doc("$max", "$v"), 3, 20),
+		accumulatorTest("first", "$first", xy, doc("$first", "$v"), 1, 10),
+		accumulatorTest("last", "$last", xy, doc("$last", "$v"), 3, 20),
+		accumulatorTest("push", "$push", xy, doc("$push", "$v"), []any{1, 3, 2, 3}, []any{10, 20}),
+		accumulatorTest("count", "$sum", xy, doc("$sum", 1), 4, 2),
+		{
+			Name:        "acc_add_to_set",
+			Category:    "expression",
+			Operation:   "$addToSet",
+			Collection:  "group_test",
+			Description: "$addToSet 去重；元素顺序不确定，断言其大小", // EN: $addToSet removes duplicates; element order is unspecified, so its size is asserted
+			Setup:       groupSetup(),
+			Action: TestAction{
+				Method: "aggregate",
+				Options: doc("pipeline", []any{
+					xy,
+					doc("$group", doc("_id", "$cat", "r", doc("$addToSet", "$v"))),
+					doc("$project", doc("r", doc("$size", "$r"))),
+					doc("$sort", doc("_id", 1)),
+				}),
+			},
+			Expected:   Expected{Count: intPtr(2), Documents: []any{doc("_id", "x", "r", 3), doc("_id", "y", "r", 2)}},
+			Comparison: &Comparison{Mode: "strict", Ordered: true},
+			Teardown:   []SetupStep{{Operation: "drop"}},
+		},
+		{
+			Name:        "acc_std_dev_pop",
+			Category:    "expression",
+			Operation:   "$stdDevPop",
+			Collection:  "group_test",
+			Description: "$stdDevPop 总体标准差", // EN: $stdDevPop population standard deviation
+			Setup:       groupSetup(),
+			Action: TestAction{
+				Method: "aggregate",
+				Options: doc("pipeline", []any{
+					doc("$match", doc("cat", "y")),
+					doc("$group", doc("_id", "$cat", "r", doc("$stdDevPop", "$v"))),
+				}),
+			},
+			Expected:   Expected{Count: intPtr(1), Documents: []any{doc("_id", "y", "r", 5.0)}},
+			Comparison: &Comparison{Mode: "strict", Ordered: true},
+			Teardown:   []SetupStep{{Operation: "drop"}},
+		},
+		{
+			Name:        "acc_null_values",
+			Category:    "expression",
+			Operation:   "$group",
+			Collection:  "group_test",
+			Description: "累加器忽略 null：$sum 为 0，$avg 和 $min 为 null", // EN: Accumulators ignore null: $sum is 0, $avg and $min are null
+			Setup:       groupSetup(),
+			Action: TestAction{
+				Method: "aggregate",
+				Options: doc("pipeline", []any{
+					doc("$match", doc("cat", "z")),
+					doc("$group", doc(
+						"_id", "$cat",
+						"sum", doc("$sum", "$v"),
+						"avg", doc("$avg", "$v"),
+						"min", doc("$min", "$v"),
+						"n", doc("$sum", 1),
+					)),
+				}),
+			},
+			Expected:   Expected{Count: intPtr(1), Documents: []any{doc("_id", "z", "sum", 0, "avg", nil, "min", nil, "n", 1)}},
+			Comparison: &Comparison{Mode: "strict", Ordered: true},
+			Teardown:   []SetupStep{{Operation: "drop"}},
+		},
+	}
+}
+
+// accumulatorTest 构造按 cat 分组后比较 x、y 两组累加结果的测试
+// EN: accumulatorTest builds a test that groups by cat and compares the accumulated values of groups x and y.
+func accumulatorTest(name, operation string, match, accumulator, wantX, wantY any) TestCase {
+	return TestCase{
+		Name:        "acc_" + name,
+		Category:    "expression",
+		Operation:   operation,
+		Collection:  "group_test",
+		Description: operation + " 累加器", // EN: accumulator
+		Setup:       groupSetup(),
+		Action: TestAction{
+			Method: "aggregate",
+			Options: doc("pipeline", []any{
+				match,
+				doc("$sort", doc("_id", 1)),
+				doc("$group", doc("_id", "$cat", "r", accumulator)),
+				doc("$sort", doc("_id", 1)),
+			}),
+		},
+		Expected: Expected{
+			Count:     intPtr(2),
+			Documents: []any{doc("_id", "x", "r", wantX), doc("_id", "y", "r", wantY)},
+		},
+		Comparison: &Comparison{Mode: "strict", Ordered: true},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
+}
+
+// groupSetup 累加器测试的前置步骤 // EN: groupSetup returns the setup steps of the accumulator tests.
+func groupSetup() []SetupStep {
+	return []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: groupDocs()},
+	}
+}
@@ -6,6 +6,7 @@ go 1.25.4
 
 require (
 	github.com/monolite/monodb v0.0.0
+	github.com/monolite/monolite-test/reference v0.0.0
 	go.mongodb.org/mongo-driver v1.17.6
 )
 
@@ -23,3 +24,5 @@ require (
 )
 
 replace github.com/monolite/monodb => ../../../MonoLite
+
+replace github.com/monolite/monolite-test/reference => ../reference
@@ -36,5 +36,17 @@ func GenerateIndexTests() []TestCase {
 			},
 			Expected: Expected{Count: intPtr(1)}, // at least _id index // EN: at least _id index
 		},
+		{
+			Name:        "index_create_compound",
+			Category:    "index",
+			Operation:   "createIndex",
+			Collection:  "index_test",
+			Description: "创建复合索引，索引名按键顺序生成", // EN: Create a compound index whose name follows key order
+			Action: TestAction{
+				Method:  "createIndex",
+				Options: doc("keys", doc("category", 1, "price", -1)),
+			},
+			Expected: Expected{Count: intPtr(1), IndexName: "category_1_price_-1"},
+		},
 	}
 }
@@ -4,7 +4,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -20,11 +19,12 @@ import (
 
 // 命令行参数 // EN: Command line arguments
 var (
-	mongoURI    = flag.String("mongo-uri", "mongodb://localhost:27017", "MongoDB 连接 URI") // EN: MongoDB connection URI
-	outputDir   = flag.String("output", "../fixtures", "测试固件输出目录")                         // EN: Test fixtures output directory
-	monoDBPath  = flag.String("monodb", "../fixtures/test.monodb", "MonoLite 数据库文件路径")    // EN: MonoLite database file path
-	dbName      = flag.String("db", "monolite_test", "测试数据库名称")                           // EN: Test database name
-	skipMongoDB = flag.Bool("skip-mongo", false, "跳过 MongoDB（仅生成 MonoLite 数据）")           // EN: Skip MongoDB (generate MonoLite data only)
+	mongoURI    = flag.String("mongo-uri", "mongodb://localhost:27017", "MongoDB 连接 URI")   // EN: MongoDB connection URI
+	outputDir   = flag.String("output", "../fixtures", "测试固件输出目录")                    // EN: Test fixtures output directory
+	monoDBPath  = flag.String("monodb", "../fixtures/test.monodb", "MonoLite 数据库文件路径") // EN: MonoLite database file path
+	dbName      = flag.String("db", "monolite_test", "测试数据库名称")                        // EN: Test database name
+	skipMongoDB = flag.Bool("skip-mongo", false, "跳过 MongoDB（仅生成 MonoLite 数据）")      // EN: Skip MongoDB (generate MonoLite data only)
+	isolate     = flag.Bool("isolate", false, "记录参考结果时每个测试前重置 MongoDB 数据库")  // EN: Reset the MongoDB database before each test when recording references
 )
 
 // main 主函数
@@ -94,6 +94,15 @@ func main() {
 	log.Println("写入基础测试数据...") // EN: Writing base test data...
 	writeBaseData(ctx, mongoDB, monoLite)
 
+	// 在 MongoDB 上执行测试用例并记录参考结果 // EN: Execute test cases against MongoDB and record reference results
+	if mongoDB != nil {
+		expectedDir := filepath.Join(*outputDir, "expected")
+		log.Printf("记录 MongoDB 参考结果到: %s", expectedDir) // EN: Recording MongoDB reference results to
+		if err := recordExpectedResults(ctx, mongoDB, testSuite, expectedDir, *isolate); err != nil {
+			log.Fatalf("记录参考结果失败: %v", err) // EN: Failed to record reference results
+		}
+	}
+
 	// 保存测试用例定义 // EN: Save test case definitions
 	testCasesPath := filepath.Join(*outputDir, "testcases.json")
 	log.Printf("保存测试用例定义到: %s", testCasesPath) // EN: Saving test case definitions to
@@ -147,6 +156,26 @@ func generateAllTests() *TestSuite {
 	tests = append(tests, txnTests...)
 	log.Printf("  事务测试: %d 个", len(txnTests)) // EN: Transaction tests: %d
 
+	// BSON 类型测试 // EN: BSON type tests
+	bsonTypeTests := GenerateBSONTypeTests()
+	tests = append(tests, bsonTypeTests...)
+	log.Printf("  BSON 类型测试: %d 个", len(bsonTypeTests)) // EN: BSON type tests: %d
+
+	// 跨类型排序测试 // EN: Cross-type ordering tests
+	typeOrderTests := GenerateTypeOrderTests()
+	tests = append(tests, typeOrderTests...)
+	log.Printf("  跨类型排序测试: %d 个", len(typeOrderTests)) // EN: Cross-type ordering tests: %d
+
+	// 表达式测试 // EN: Expression tests
+	exprTests := GenerateExpressionTests()
+	tests = append(tests, exprTests...)
+	log.Printf("  表达式测试: %d 个", len(exprTests)) // EN: Expression tests: %d
+
+	// 场景测试 // EN: Scenario tests
+	scenarioTests := GenerateScenarioTests()
+	tests = append(tests, scenarioTests...)
+	log.Printf("  场景测试: %d 个", len(scenarioTests)) // EN: Scenario tests: %d
+
 	return &TestSuite{
 		Version:   "1.0.0",
 		Generated: time.Now().Format(time.RFC3339),
@@ -157,25 +186,12 @@ func generateAllTests() *TestSuite {
 // writeBaseData 写入基础测试数据
 // EN: writeBaseData writes base test data to both databases.
 func writeBaseData(ctx context.Context, mongoDB *mongo.Database, monoLite *engine.Database) {
-	// 创建一个包含各种 BSON 类型的基础集合 // EN: Create a base collection with various BSON types
-	baseData := []bson.D{
-		{{Key: "_id", Value: "base_001"}, {Key: "type", Value: "string"}, {Key: "value", Value: "hello world"}},
-		{{Key: "_id", Value: "base_002"}, {Key: "type", Value: "int32"}, {Key: "value", Value: int32(42)}},
-		{{Key: "_id", Value: "base_003"}, {Key: "type", Value: "int64"}, {Key: "value", Value: int64(9007199254740993)}},
-		{{Key: "_id", Value: "base_004"}, {Key: "type", Value: "double"}, {Key: "value", Value: 3.14159}},
-		{{Key: "_id", Value: "base_005"}, {Key: "type", Value: "bool"}, {Key: "value", Value: true}},
-		{{Key: "_id", Value: "base_006"}, {Key: "type", Value: "null"}, {Key: "value", Value: nil}},
-		{{Key: "_id", Value: "base_007"}, {Key: "type", Value: "array"}, {Key: "value", Value: bson.A{1, 2, 3}}},
-		{{Key: "_id", Value: "base_008"}, {Key: "type", Value: "document"}, {Key: "value", Value: bson.D{{Key: "nested", Value: "value"}}}},
-	}
+	baseData := baseDocuments()
 
 	// 写入 MongoDB（如果可用）// EN: Write to MongoDB (if available)
 	if mongoDB != nil {
-		mongoCol := mongoDB.Collection("base")
-		for _, doc := range baseData {
-			if _, err := mongoCol.InsertOne(ctx, doc); err != nil {
-				log.Printf("警告: MongoDB 插入失败: %v", err) // EN: Warning: MongoDB insert failed
-			}
+		if err := insertMongoBaseData(ctx, mongoDB, baseData); err != nil {
+			log.Printf("警告: MongoDB 插入失败: %v", err) // EN: Warning: MongoDB insert failed
 		}
 	}
 
@@ -194,12 +210,40 @@ func writeBaseData(ctx context.Context, mongoDB *mongo.Database, monoLite *engin
 	log.Printf("  基础数据: %d 条文档", len(baseData)) // EN: Base data: %d documents
 }
 
-// saveJSON 保存 JSON 文件
-// EN: saveJSON saves data to a JSON file.
+// baseDocuments 返回基础集合的文档
+// EN: baseDocuments returns the documents of the base collection.
+func baseDocuments() []bson.D {
+	// 创建一个包含各种 BSON 类型的基础集合 // EN: Create a base collection with various BSON types
+	return []bson.D{
+		{{Key: "_id", Value: "base_001"}, {Key: "type", Value: "string"}, {Key: "value", Value: "hello world"}},
+		{{Key: "_id", Value: "base_002"}, {Key: "type", Value: "int32"}, {Key: "value", Value: int32(42)}},
+		{{Key: "_id", Value: "base_003"}, {Key: "type", Value: "int64"}, {Key: "value", Value: int64(9007199254740993)}},
+		{{Key: "_id", Value: "base_004"}, {Key: "type", Value: "double"}, {Key: "value", Value: 3.14159}},
+		{{Key: "_id", Value: "base_005"}, {Key: "type", Value: "bool"}, {Key: "value", Value: true}},
+		{{Key: "_id", Value: "base_006"}, {Key: "type", Value: "null"}, {Key: "value", Value: nil}},
+		{{Key: "_id", Value: "base_007"}, {Key: "type", Value: "array"}, {Key: "value", Value: bson.A{1, 2, 3}}},
+		{{Key: "_id", Value: "base_008"}, {Key: "type", Value: "document"}, {Key: "value", Value: bson.D{{Key: "nested", Value: "value"}}}},
+	}
+}
+
+// insertMongoBaseData 将基础数据写入 MongoDB
+// EN: insertMongoBaseData writes the base data to MongoDB.
+func insertMongoBaseData(ctx context.Context, mongoDB *mongo.Database, baseData []bson.D) error {
+	mongoCol := mongoDB.Collection("base")
+	for _, doc := range baseData {
+		if _, err := mongoCol.InsertOne(ctx, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveJSON 以规范 Extended JSON v2 保存文件，保留 BSON 类型和字段顺序
+// EN: saveJSON saves data as canonical Extended JSON v2, preserving BSON types and field order.
 func saveJSON(path string, v any) error {
-	data, err := json.MarshalIndent(v, "", "  ")
+	data, err := bson.MarshalExtJSONIndent(v, true, false, "", "  ")
 	if err != nil {
-		return fmt.Errorf("JSON 序列化失败: %w", err) // EN: JSON serialization failed
+		return fmt.Errorf("Extended JSON 序列化失败: %w", err) // EN: Extended JSON serialization failed
 	}
 	return os.WriteFile(path, data, 0644)
 }
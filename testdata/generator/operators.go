@@ -2,10 +2,15 @@
 
 package main
 
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 // GenerateUpdateOperatorTests 生成更新操作符测试
 // EN: GenerateUpdateOperatorTests generates update operator test cases.
 func GenerateUpdateOperatorTests() []TestCase {
-	return []TestCase{
+	tests := []TestCase{
 		{
 			Name:        "update_op_set",
 			Category:    "update_op",
@@ -21,6 +26,10 @@ func GenerateUpdateOperatorTests() []TestCase {
 				Update: doc("$set", doc("name", "updated")),
 			},
 			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", "set_001"),
+				Documents: []any{doc("_id", "set_001", "name", "updated")},
+			},
 		},
 		{
 			Name:        "update_op_inc",
@@ -37,6 +46,10 @@ func GenerateUpdateOperatorTests() []TestCase {
 				Update: doc("$inc", doc("count", 5)),
 			},
 			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", "inc_001"),
+				Documents: []any{doc("_id", "inc_001", "count", 15)},
+			},
 		},
 		{
 			Name:        "update_op_push",
@@ -53,14 +66,314 @@ func GenerateUpdateOperatorTests() []TestCase {
 				Update: doc("$push", doc("items", "c")),
 			},
 			Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+			Verify: &Verify{
+				Filter:    doc("_id", "push_001"),
+				Documents: []any{doc("_id", "push_001", "items", []any{"a", "b", "c"})},
+			},
+		},
+	}
+	return append(tests, generateUpdateMatrixTests()...)
+}
+
+// updateOpCase 更新操作符矩阵中的单个用例
+// EN: updateOpCase is a single case of the update operator matrix.
+type updateOpCase struct {
+	name        string  // 测试名称 // EN: Test name
+	operation   string  // 被测操作符 // EN: Operator under test
+	description string  // 描述 // EN: Description
+	before      bson.D  // 更新前的文档，为空时不插入 // EN: Document before the update, nothing is inserted when nil
+	filter      bson.D  // 更新条件，默认按 before 的 _id // EN: Update filter, defaults to the _id of before
+	update      bson.D  // 更新内容 // EN: Update document
+	options     bson.D  // 更新选项 // EN: Update options
+	unchanged   bool    // 匹配但不修改文档 // EN: The document matches but is not modified
+	upsertedID  any     // upsert 插入的 _id // EN: _id inserted by an upsert
+	after       bson.D  // 更新后的文档 // EN: Document after the update
+	verify      *Verify // 自定义状态校验，用于无法精确预期的值 // EN: Custom verification for values that cannot be predicted exactly
+}
+
+// generateUpdateMatrixTests 生成更新操作符矩阵测试，每个测试都校验更新后的完整文档
+// 使用严格比较，新增字段的位置和数值类型也会被检查
+// EN: generateUpdateMatrixTests generates the update operator matrix; every test verifies the full document after the update.
+// EN: Strict comparison is used, so the position of new fields and numeric types are checked as well.
+func generateUpdateMatrixTests() []TestCase {
+	cases := []updateOpCase{
+		// 字段操作符 // EN: Field operators
+		{
+			name: "update_op_unset", operation: "$unset", description: "$unset 删除字段", // EN: $unset removes a field
+			before: doc("_id", "u_unset", "a", 1, "b", 2, "c", 3),
+			update: doc("$unset", doc("b", "")),
+			after:  doc("_id", "u_unset", "a", 1, "c", 3),
+		},
+		{
+			name: "update_op_unset_nested", operation: "$unset", description: "$unset 删除嵌套字段", // EN: $unset removes a nested field
+			before: doc("_id", "u_unset_nested", "a", doc("x", 1, "y", 2)),
+			update: doc("$unset", doc("a.x", "")),
+			after:  doc("_id", "u_unset_nested", "a", doc("y", 2)),
+		},
+		{
+			name: "update_op_mul", operation: "$mul", description: "$mul 整数相乘保持 int32", // EN: $mul of integers stays int32
+			before: doc("_id", "u_mul", "n", 5),
+			update: doc("$mul", doc("n", 3)),
+			after:  doc("_id", "u_mul", "n", 15),
+		},
+		{
+			name: "update_op_mul_double", operation: "$mul", description: "$mul 乘以浮点数得到 double", // EN: $mul by a double yields a double
+			before: doc("_id", "u_mul_double", "n", 5),
+			update: doc("$mul", doc("n", 1.5)),
+			after:  doc("_id", "u_mul_double", "n", 7.5),
+		},
+		{
+			name: "update_op_mul_missing", operation: "$mul", description: "$mul 缺失字段设为 0", // EN: $mul sets a missing field to 0
+			before: doc("_id", "u_mul_missing", "a", 1),
+			update: doc("$mul", doc("m", int64(2))),
+			after:  doc("_id", "u_mul_missing", "a", 1, "m", int64(0)),
+		},
+		{
+			name: "update_op_min", operation: "$min", description: "$min 新值更小时更新", // EN: $min updates when the new value is smaller
+			before: doc("_id", "u_min", "v", 10),
+			update: doc("$min", doc("v", 5)),
+			after:  doc("_id", "u_min", "v", 5),
+		},
+		{
+			name: "update_op_min_noop", operation: "$min", description: "$min 新值更大时不修改", // EN: $min leaves the document unchanged when the new value is larger
+			before: doc("_id", "u_min_noop", "v", 10),
+			update: doc("$min", doc("v", 20)), unchanged: true,
+			after: doc("_id", "u_min_noop", "v", 10),
+		},
+		{
+			name: "update_op_max", operation: "$max", description: "$max 新值更大时更新", // EN: $max updates when the new value is larger
+			before: doc("_id", "u_max", "v", 10),
+			update: doc("$max", doc("v", 20)),
+			after:  doc("_id", "u_max", "v", 20),
+		},
+		{
+			name: "update_op_rename", operation: "$rename", description: "$rename 重命名后的字段移到末尾", // EN: $rename moves the renamed field to the end
+			before: doc("_id", "u_rename", "a", 1, "b", 2),
+			update: doc("$rename", doc("a", "z")),
+			after:  doc("_id", "u_rename", "b", 2, "z", 1),
+		},
+		{
+			name: "update_op_rename_nested", operation: "$rename", description: "$rename 将嵌套字段移到顶层", // EN: $rename moves a nested field to the top level
+			before: doc("_id", "u_rename_nested", "a", doc("x", 1)),
+			update: doc("$rename", doc("a.x", "y")),
+			after:  doc("_id", "u_rename_nested", "a", doc(), "y", 1),
+		},
+		{
+			name: "update_op_current_date", operation: "$currentDate", description: "$currentDate 设置为当前日期", // EN: $currentDate sets the current date
+			before: doc("_id", "u_current_date", "v", 1),
+			update: doc("$currentDate", doc("ts", true)),
+			verify: &Verify{
+				Filter:    doc("_id", "u_current_date", "ts", doc("$type", "date")),
+				Options:   doc("projection", doc("ts", 0)),
+				Documents: []any{doc("_id", "u_current_date", "v", 1)},
+			},
+		},
+		{
+			name: "update_op_current_date_timestamp", operation: "$currentDate", description: "$currentDate 指定 timestamp 类型", // EN: $currentDate with the timestamp type
+			before: doc("_id", "u_current_ts", "v", 1),
+			update: doc("$currentDate", doc("ts", doc("$type", "timestamp"))),
+			verify: &Verify{
+				Filter:    doc("_id", "u_current_ts", "ts", doc("$type", "timestamp")),
+				Options:   doc("projection", doc("ts", 0)),
+				Documents: []any{doc("_id", "u_current_ts", "v", 1)},
+			},
+		},
+		{
+			name: "update_op_set_on_insert", operation: "$setOnInsert", description: "$setOnInsert 在 upsert 插入时生效", // EN: $setOnInsert applies when an upsert inserts
+			filter:     doc("_id", "u_soi_new"),
+			update:     doc("$setOnInsert", doc("created", true)),
+			options:    doc("upsert", true),
+			upsertedID: "u_soi_new",
+			after:      doc("_id", "u_soi_new", "created", true),
+		},
+		{
+			name: "update_op_set_on_insert_existing", operation: "$setOnInsert", description: "$setOnInsert 在更新已有文档时被忽略", // EN: $setOnInsert is ignored when an existing document is updated
+			before:  doc("_id", "u_soi_existing", "a", 1),
+			update:  doc("$set", doc("a", 2), "$setOnInsert", doc("created", true)),
+			options: doc("upsert", true),
+			after:   doc("_id", "u_soi_existing", "a", 2),
+		},
+
+		// 数组操作符 // EN: Array operators
+		{
+			name: "update_op_add_to_set_each", operation: "$addToSet", description: "$addToSet 配合 $each 只添加不存在的值", // EN: $addToSet with $each only adds missing values
+			before: doc("_id", "u_add_each", "tags", []any{"a", "b"}),
+			update: doc("$addToSet", doc("tags", doc("$each", []any{"b", "c", "d"}))),
+			after:  doc("_id", "u_add_each", "tags", []any{"a", "b", "c", "d"}),
+		},
+		{
+			name: "update_op_add_to_set_existing", operation: "$addToSet", description: "$addToSet 值已存在时不修改", // EN: $addToSet leaves the document unchanged when the value exists
+			before: doc("_id", "u_add_existing", "tags", []any{"a", "b"}),
+			update: doc("$addToSet", doc("tags", "a")), unchanged: true,
+			after: doc("_id", "u_add_existing", "tags", []any{"a", "b"}),
+		},
+		{
+			name: "update_op_push_slice", operation: "$push", description: "$push 配合 $each 和 $slice 保留最后几个元素", // EN: $push with $each and $slice keeps the last elements
+			before: doc("_id", "u_push_slice", "s", []any{1, 2, 3}),
+			update: doc("$push", doc("s", doc("$each", []any{4, 5}, "$slice", -3))),
+			after:  doc("_id", "u_push_slice", "s", []any{3, 4, 5}),
+		},
+		{
+			name: "update_op_push_sort", operation: "$push", description: "$push 配合 $sort 排序数组", // EN: $push with $sort sorts the array
+			before: doc("_id", "u_push_sort", "s", []any{3, 1}),
+			update: doc("$push", doc("s", doc("$each", []any{2}, "$sort", 1))),
+			after:  doc("_id", "u_push_sort", "s", []any{1, 2, 3}),
+		},
+		{
+			name: "update_op_push_sort_field", operation: "$push", description: "$push 按子文档字段排序", // EN: $push sorts subdocuments by a field
+			before: doc("_id", "u_push_sort_field", "s", []any{doc("n", "b", "v", 2), doc("n", "a", "v", 1)}),
+			update: doc("$push", doc("s", doc("$each", []any{doc("n", "c", "v", 3)}, "$sort", doc("v", -1)))),
+			after:  doc("_id", "u_push_sort_field", "s", []any{doc("n", "c", "v", 3), doc("n", "b", "v", 2), doc("n", "a", "v", 1)}),
+		},
+		{
+			name: "update_op_push_position", operation: "$push", description: "$push 配合 $position 插入到指定位置", // EN: $push with $position inserts at the given position
+			before: doc("_id", "u_push_position", "s", []any{"a", "d"}),
+			update: doc("$push", doc("s", doc("$each", []any{"b", "c"}, "$position", 1))),
+			after:  doc("_id", "u_push_position", "s", []any{"a", "b", "c", "d"}),
+		},
+		{
+			name: "update_op_pop_last", operation: "$pop", description: "$pop 1 删除最后一个元素", // EN: $pop 1 removes the last element
+			before: doc("_id", "u_pop_last", "s", []any{1, 2, 3}),
+			update: doc("$pop", doc("s", 1)),
+			after:  doc("_id", "u_pop_last", "s", []any{1, 2}),
+		},
+		{
+			name: "update_op_pop_first", operation: "$pop", description: "$pop -1 删除第一个元素", // EN: $pop -1 removes the first element
+			before: doc("_id", "u_pop_first", "s", []any{1, 2, 3}),
+			update: doc("$pop", doc("s", -1)),
+			after:  doc("_id", "u_pop_first", "s", []any{2, 3}),
 		},
+		{
+			name: "update_op_pull", operation: "$pull", description: "$pull 删除所有相等的值", // EN: $pull removes every equal value
+			before: doc("_id", "u_pull", "s", []any{1, 2, 3, 2}),
+			update: doc("$pull", doc("s", 2)),
+			after:  doc("_id", "u_pull", "s", []any{1, 3}),
+		},
+		{
+			name: "update_op_pull_condition", operation: "$pull", description: "$pull 按条件删除", // EN: $pull removes by condition
+			before: doc("_id", "u_pull_condition", "s", []any{1, 5, 10, 15}),
+			update: doc("$pull", doc("s", doc("$gte", 10))),
+			after:  doc("_id", "u_pull_condition", "s", []any{1, 5}),
+		},
+		{
+			name: "update_op_pull_subdoc", operation: "$pull", description: "$pull 按子文档条件删除", // EN: $pull removes subdocuments matching a condition
+			before: doc("_id", "u_pull_subdoc", "items", []any{doc("sku", "a", "qty", 1), doc("sku", "b", "qty", 2)}),
+			update: doc("$pull", doc("items", doc("sku", "a"))),
+			after:  doc("_id", "u_pull_subdoc", "items", []any{doc("sku", "b", "qty", 2)}),
+		},
+		{
+			name: "update_op_pull_all", operation: "$pullAll", description: "$pullAll 删除列出的所有值", // EN: $pullAll removes every listed value
+			before: doc("_id", "u_pull_all", "s", []any{1, 2, 3, 1, 4}),
+			update: doc("$pullAll", doc("s", []any{1, 4})),
+			after:  doc("_id", "u_pull_all", "s", []any{2, 3}),
+		},
+
+		// 位置操作符 // EN: Positional operators
+		{
+			name: "update_op_positional", operation: "$", description: "$ 更新第一个匹配的数组元素", // EN: $ updates the first matching array element
+			before: doc("_id", "u_positional", "grades", []any{70, 80, 90, 80}),
+			filter: doc("_id", "u_positional", "grades", 80),
+			update: doc("$set", doc("grades.$", 82)),
+			after:  doc("_id", "u_positional", "grades", []any{70, 82, 90, 80}),
+		},
+		{
+			name: "update_op_positional_subdoc", operation: "$", description: "$ 更新匹配的子文档字段", // EN: $ updates a field of the matching subdocument
+			before: doc("_id", "u_positional_subdoc", "items", []any{doc("sku", "a", "qty", 1), doc("sku", "b", "qty", 2)}),
+			filter: doc("_id", "u_positional_subdoc", "items.sku", "b"),
+			update: doc("$inc", doc("items.$.qty", 10)),
+			after:  doc("_id", "u_positional_subdoc", "items", []any{doc("sku", "a", "qty", 1), doc("sku", "b", "qty", 12)}),
+		},
+		{
+			name: "update_op_all_positional", operation: "$[]", description: "$[] 更新所有数组元素", // EN: $[] updates every array element
+			before: doc("_id", "u_all_positional", "s", []any{1, 2, 3}),
+			update: doc("$inc", doc("s.$[]", 10)),
+			after:  doc("_id", "u_all_positional", "s", []any{11, 12, 13}),
+		},
+		{
+			name: "update_op_all_positional_subdoc", operation: "$[]", description: "$[] 为每个子文档添加字段", // EN: $[] adds a field to every subdocument
+			before: doc("_id", "u_all_positional_subdoc", "items", []any{doc("sku", "a"), doc("sku", "b")}),
+			update: doc("$set", doc("items.$[].done", true)),
+			after:  doc("_id", "u_all_positional_subdoc", "items", []any{doc("sku", "a", "done", true), doc("sku", "b", "done", true)}),
+		},
+		{
+			name: "update_op_filtered_positional", operation: "$[<id>]", description: "$[<id>] 配合 arrayFilters 更新满足条件的元素", // EN: $[<id>] with arrayFilters updates the matching elements
+			before:  doc("_id", "u_filtered", "grades", []any{95, 85, 92, 70}),
+			update:  doc("$set", doc("grades.$[g]", 100)),
+			options: doc("arrayFilters", []any{doc("g", doc("$gte", 90))}),
+			after:   doc("_id", "u_filtered", "grades", []any{100, 85, 100, 70}),
+		},
+		{
+			name: "update_op_filtered_positional_subdoc", operation: "$[<id>]", description: "$[<id>] 按子文档字段过滤", // EN: $[<id>] filters by a subdocument field
+			before:  doc("_id", "u_filtered_subdoc", "items", []any{doc("sku", "a", "qty", 1), doc("sku", "b", "qty", 5)}),
+			update:  doc("$inc", doc("items.$[it].qty", 1)),
+			options: doc("arrayFilters", []any{doc("it.qty", doc("$gt", 2))}),
+			after:   doc("_id", "u_filtered_subdoc", "items", []any{doc("sku", "a", "qty", 1), doc("sku", "b", "qty", 6)}),
+		},
+	}
+
+	tests := make([]TestCase, len(cases))
+	for i, c := range cases {
+		tests[i] = updateOpTest(c)
 	}
+	return tests
+}
+
+// updateOpTest 根据矩阵用例构造 updateOne 测试，并在更新后按 _id 查询校验文档
+// EN: updateOpTest builds an updateOne test from a matrix case and verifies the document by _id after the update.
+func updateOpTest(c updateOpCase) TestCase {
+	setup := []SetupStep{{Operation: "drop"}}
+	if c.before != nil {
+		setup = append(setup, SetupStep{Operation: "insert", Data: c.before})
+	}
+
+	filter := c.filter
+	if filter == nil {
+		filter = doc("_id", c.before[0].Value)
+	}
+
+	expected := Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)}
+	switch {
+	case c.upsertedID != nil:
+		expected = Expected{MatchedCount: intPtr(0), ModifiedCount: intPtr(0), UpsertedID: c.upsertedID}
+	case c.unchanged:
+		expected.ModifiedCount = intPtr(0)
+	}
+
+	verify := c.verify
+	if verify == nil {
+		verify = &Verify{
+			Filter:    doc("_id", c.after[0].Value),
+			Documents: []any{c.after},
+		}
+	}
+
+	tc := TestCase{
+		Name:        c.name,
+		Category:    "update_op",
+		Operation:   c.operation,
+		Collection:  "update_matrix",
+		Description: c.description,
+		Setup:       setup,
+		Action: TestAction{
+			Method: "updateOne",
+			Filter: filter,
+			Update: c.update,
+		},
+		Expected:   expected,
+		Verify:     verify,
+		Comparison: &Comparison{Mode: "strict"},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
+	if c.options != nil {
+		tc.Action.Options = c.options
+	}
+	return tc
 }
 
 // GenerateQueryOperatorTests 生成查询操作符测试
 // EN: GenerateQueryOperatorTests generates query operator test cases.
 func GenerateQueryOperatorTests() []TestCase {
-	return []TestCase{
+	tests := []TestCase{
 		{
 			Name:        "query_op_eq",
 			Category:    "query_op",
@@ -103,4 +416,112 @@ func GenerateQueryOperatorTests() []TestCase {
 			Expected: Expected{Count: intPtr(2)},
 		},
 	}
+	return append(tests, generateQueryMatrixTests()...)
+}
+
+// queryMatrixDocs 查询操作符矩阵使用的数据集
+// 覆盖数值类型混用、缺失字段、空数组以及子文档数组
+// EN: queryMatrixDocs is the data set used by the query operator matrix.
+// EN: It covers mixed numeric types, missing fields, empty arrays and arrays of subdocuments.
+func queryMatrixDocs() []any {
+	return []any{
+		doc("_id", "q01", "name", "Apple", "qty", 5, "tags", []any{"fruit", "red"},
+			"items", []any{doc("sku", "a", "qty", 2), doc("sku", "b", "qty", 8)}, "note", nil),
+		doc("_id", "q02", "name", "banana", "qty", 12, "tags", []any{"fruit", "yellow"},
+			"items", []any{doc("sku", "a", "qty", 10)}),
+		doc("_id", "q03", "name", "carrot", "qty", 0, "tags", []any{"vegetable", "orange", "red"},
+			"items", []any{}),
+		doc("_id", "q04", "name", "Date", "qty", 7.5, "tags", []any{}),
+		doc("_id", "q05", "name", "eggplant", "qty", "20", "tags", []any{"vegetable"},
+			"items", []any{doc("sku", "c", "qty", 3), doc("sku", "a", "qty", 9)}),
+		doc("_id", "q06", "name", "fig"),
+	}
+}
+
+// generateQueryMatrixTests 生成查询操作符矩阵测试，每个测试断言完整的结果集
+// EN: generateQueryMatrixTests generates the query operator matrix; every test asserts the full result set.
+func generateQueryMatrixTests() []TestCase {
+	return []TestCase{
+		// 比较操作符 // EN: Comparison operators
+		queryMatrixTest("query_op_ne", "$ne", "$ne 同时匹配缺失字段", doc("qty", doc("$ne", 5)), "q02", "q03", "q04", "q05", "q06"),                   // EN: $ne also matches missing fields
+		queryMatrixTest("query_op_gte", "$gte", "$gte 跨整数与浮点数", doc("qty", doc("$gte", 7.5)), "q02", "q04"),                                   // EN: $gte across integers and doubles
+		queryMatrixTest("query_op_lt", "$lt", "$lt 不匹配字符串类型的值", doc("qty", doc("$lt", 5)), "q03"),                                             // EN: $lt does not match string values
+		queryMatrixTest("query_op_lte", "$lte", "$lte 包含边界值", doc("qty", doc("$lte", 5)), "q01", "q03"),                                       // EN: $lte includes the boundary value
+		queryMatrixTest("query_op_eq_array", "$eq", "标量条件匹配数组元素", doc("tags", "red"), "q01", "q03"),                                           // EN: A scalar condition matches array elements
+		queryMatrixTest("query_op_nin", "$nin", "$nin 同时匹配缺失字段", doc("name", doc("$nin", []any{"Apple", "fig"})), "q02", "q03", "q04", "q05"), // EN: $nin also matches missing fields
+		queryMatrixTest("query_op_nin_array", "$nin", "$nin 排除包含任一值的数组", doc("tags", doc("$nin", []any{"red"})), "q02", "q04", "q05", "q06"),  // EN: $nin excludes arrays containing any of the values
+
+		// 逻辑操作符 // EN: Logical operators
+		queryMatrixTest("query_op_and", "$and", "$and 组合条件",
+			doc("$and", []any{doc("qty", doc("$gt", 0)), doc("tags", "fruit")}), "q01", "q02"), // EN: $and combined conditions
+		queryMatrixTest("query_op_or", "$or", "$or 任一条件匹配",
+			doc("$or", []any{doc("qty", 0), doc("name", "fig")}), "q03", "q06"), // EN: $or matches either condition
+		queryMatrixTest("query_op_nor", "$nor", "$nor 所有条件都不匹配",
+			doc("$nor", []any{doc("tags", "fruit"), doc("qty", doc("$type", "string"))}), "q03", "q04", "q06"), // EN: $nor matches when no condition matches
+		queryMatrixTest("query_op_not", "$not", "$not 匹配缺失字段和类型不同的值",
+			doc("qty", doc("$not", doc("$gt", 5))), "q01", "q03", "q05", "q06"), // EN: $not matches missing fields and values of other types
+		queryMatrixTest("query_op_not_regex", "$not", "$not 与正则表达式组合",
+			doc("name", doc("$not", primitive.Regex{Pattern: "^[a-c]"})), "q01", "q04", "q05", "q06"), // EN: $not combined with a regular expression
+
+		// 元素操作符 // EN: Element operators
+		queryMatrixTest("query_op_exists_true", "$exists", "$exists: true 匹配值为 null 的字段", doc("note", doc("$exists", true)), "q01"),                 // EN: $exists: true matches fields whose value is null
+		queryMatrixTest("query_op_exists_false", "$exists", "$exists: false", doc("items", doc("$exists", false)), "q04", "q06"),                    // EN: $exists: false
+		queryMatrixTest("query_op_type_double", "$type", "$type 按类型别名匹配", doc("qty", doc("$type", "double")), "q04"),                                // EN: $type matches by type alias
+		queryMatrixTest("query_op_type_number", "$type", "$type number 匹配所有数值类型", doc("qty", doc("$type", "number")), "q01", "q02", "q03", "q04"),   // EN: $type number matches every numeric type
+		queryMatrixTest("query_op_type_code", "$type", "$type 按类型编号匹配", doc("qty", doc("$type", 2)), "q05"),                                         // EN: $type matches by type number
+		queryMatrixTest("query_op_type_array", "$type", "$type array 匹配空数组", doc("tags", doc("$type", "array")), "q01", "q02", "q03", "q04", "q05"), // EN: $type array matches empty arrays
+		queryMatrixTest("query_op_type_null", "$type", "$type null 不匹配缺失字段", doc("note", doc("$type", "null")), "q01"),                              // EN: $type null does not match missing fields
+
+		// 求值操作符 // EN: Evaluation operators
+		queryMatrixTest("query_op_regex", "$regex", "$regex 区分大小写", doc("name", doc("$regex", "^[a-d]")), "q02", "q03"),                                                      // EN: $regex is case sensitive
+		queryMatrixTest("query_op_regex_options", "$regex", "$regex 配合 $options i 忽略大小写", doc("name", doc("$regex", "^[a-d]", "$options", "i")), "q01", "q02", "q03", "q04"), // EN: $regex with $options i ignores case
+		queryMatrixTest("query_op_regex_array", "$regex", "$regex 匹配数组元素", doc("tags", doc("$regex", "^veg")), "q03", "q05"),                                                 // EN: $regex matches array elements
+		queryMatrixTest("query_op_mod", "$mod", "$mod 不匹配浮点余数和字符串", doc("qty", doc("$mod", []any{5, 0})), "q01", "q03"),                                                      // EN: $mod skips doubles with a remainder and strings
+
+		// 数组操作符 // EN: Array operators
+		queryMatrixTest("query_op_size", "$size", "$size 精确匹配数组长度", doc("tags", doc("$size", 2)), "q01", "q02"),                                                               // EN: $size matches the exact array length
+		queryMatrixTest("query_op_size_zero", "$size", "$size 0 匹配空数组", doc("tags", doc("$size", 0)), "q04"),                                                                  // EN: $size 0 matches empty arrays
+		queryMatrixTest("query_op_all", "$all", "$all 要求包含所有值", doc("tags", doc("$all", []any{"red", "vegetable"})), "q03"),                                                   // EN: $all requires every value
+		queryMatrixTest("query_op_all_single", "$all", "$all 单个值", doc("tags", doc("$all", []any{"fruit"})), "q01", "q02"),                                                    // EN: $all with a single value
+		queryMatrixTest("query_op_elem_match", "$elemMatch", "$elemMatch 要求同一元素满足所有条件", doc("items", doc("$elemMatch", doc("sku", "a", "qty", doc("$gt", 5)))), "q02", "q05"), // EN: $elemMatch requires one element to satisfy every condition
+
+		// 点号路径 // EN: Dot notation
+		queryMatrixTest("query_op_dot_array", "dot", "点号路径匹配子文档数组中的任一元素", doc("items.sku", "c"), "q05"),                                          // EN: A dotted path matches any element of an array of subdocuments
+		queryMatrixTest("query_op_dot_range", "dot", "点号路径上的范围条件", doc("items.qty", doc("$gte", 9)), "q02", "q05"),                               // EN: A range condition on a dotted path
+		queryMatrixTest("query_op_dot_index", "dot", "点号路径按数组下标匹配", doc("items.1.qty", 8), "q01"),                                                // EN: A dotted path matches by array index
+		queryMatrixTest("query_op_dot_multi", "dot", "多个点号条件可由不同元素分别满足", doc("items.sku", "a", "items.qty", doc("$gt", 5)), "q01", "q02", "q05"), // EN: Several dotted conditions may be satisfied by different elements
+	}
+}
+
+// queryMatrixTest 构造在 query_matrix 数据集上查询并按 _id 顺序断言结果集的测试
+// EN: queryMatrixTest builds a test that queries the query_matrix data set and asserts the result set in _id order.
+func queryMatrixTest(name, operation, description string, filter any, ids ...string) TestCase {
+	byID := make(map[string]any)
+	for _, d := range queryMatrixDocs() {
+		byID[d.(bson.D)[0].Value.(string)] = d
+	}
+	expected := make([]any, len(ids))
+	for i, id := range ids {
+		expected[i] = byID[id]
+	}
+
+	return TestCase{
+		Name:        name,
+		Category:    "query_op",
+		Operation:   operation,
+		Collection:  "query_matrix",
+		Description: description,
+		Setup: []SetupStep{
+			{Operation: "drop"},
+			{Operation: "insertMany", Data: queryMatrixDocs()},
+		},
+		Action: TestAction{
+			Method:  "find",
+			Filter:  filter,
+			Options: doc("sort", doc("_id", 1)),
+		},
+		Expected:   Expected{Count: intPtr(int64(len(ids))), Documents: expected},
+		Comparison: &Comparison{Ordered: true},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
 }
@@ -0,0 +1,723 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/monolite/monolite-test/reference"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExpectedResult 在 MongoDB 上记录的参考结果，格式与运行器共用
+// EN: ExpectedResult is the reference result recorded against MongoDB; the format is shared with the runners.
+type ExpectedResult = reference.ExpectedResult
+
+// recordExpectedResults 在 MongoDB 上执行所有测试用例并保存参考结果；
+// isolate 为真时每个测试前都会重置数据库，与运行器的 --isolate 模式对应
+// EN: recordExpectedResults executes every test case against MongoDB and saves the reference results;
+// EN: when isolate is true the database is reset before each test, matching the runner's --isolate mode.
+func recordExpectedResults(ctx context.Context, db *mongo.Database, suite *TestSuite, dir string, isolate bool) error {
+	recorded, failed, skipped := 0, 0, 0
+	txnSupported := supportsTransactions(ctx, db)
+	if !txnSupported {
+		log.Printf("  MongoDB 不是副本集或分片集群，不支持事务，跳过事务测试") // EN: MongoDB is neither a replica set nor a sharded cluster and does not support transactions, skipping transaction tests
+	}
+	for _, tc := range suite.Tests {
+		path := reference.Path(dir, tc.Name)
+		if tc.Transaction != nil && !txnSupported {
+			// 删除以前记录的结果，以免运行器使用过期的参考结果 // EN: Remove any earlier recording so runners do not use a stale reference
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("删除参考结果失败 %s: %w", tc.Name, err) // EN: Failed to remove reference result
+			}
+			skipped++
+			continue
+		}
+		if isolate {
+			if err := resetMongoDatabase(ctx, db); err != nil {
+				return fmt.Errorf("重置 MongoDB 数据库失败: %w", err) // EN: Failed to reset MongoDB database
+			}
+		}
+
+		result := executeOnMongo(ctx, db, tc)
+		if result.Error != "" {
+			failed++
+		}
+
+		if err := saveJSON(path, result); err != nil {
+			return fmt.Errorf("保存参考结果失败 %s: %w", tc.Name, err) // EN: Failed to save reference result
+		}
+		recorded++
+	}
+	log.Printf("  参考结果: %d 个 (其中 %d 个返回错误)，跳过 %d 个", recorded, failed, skipped) // EN: Reference results: %d (%d returned errors), %d skipped
+	return nil
+}
+
+// supportsTransactions 判断 MongoDB 是否支持事务：只有副本集和分片集群支持，单机 mongod 不支持
+// EN: supportsTransactions reports whether MongoDB supports transactions: only replica sets and sharded clusters do, a standalone mongod does not.
+func supportsTransactions(ctx context.Context, db *mongo.Database) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		log.Printf("  警告: hello 命令失败，视为不支持事务: %v", err) // EN: Warning: hello command failed, treating transactions as unsupported
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// resetMongoDatabase 删除数据库并重新写入基础数据
+// EN: resetMongoDatabase drops the database and writes the base data again.
+func resetMongoDatabase(ctx context.Context, db *mongo.Database) error {
+	if err := db.Drop(ctx); err != nil {
+		return err
+	}
+	return insertMongoBaseData(ctx, db, baseDocuments())
+}
+
+// executeOnMongo 在 MongoDB 上执行单个测试用例
+// EN: executeOnMongo executes a single test case against MongoDB.
+func executeOnMongo(ctx context.Context, db *mongo.Database, tc TestCase) *ExpectedResult {
+	result := &ExpectedResult{TestName: tc.Name, Source: reference.SourceMongoDB}
+	col := db.Collection(tc.Collection)
+
+	if err := executeMongoSteps(ctx, db, tc.Collection, tc.Setup); err != nil {
+		recordError(result, fmt.Errorf("Setup 失败: %w", err)) // EN: Setup failed
+	} else {
+		var err error
+		switch {
+		case tc.Transaction != nil:
+			err = executeMongoTransaction(ctx, db, tc, result)
+		case tc.Steps != nil:
+			err = executeMongoScenario(ctx, db, tc, result)
+		default:
+			err = executeMongoAction(ctx, db, col, tc.Action, result)
+		}
+		if err != nil {
+			recordError(result, err)
+		}
+		if tc.Verify != nil {
+			if err := recordVerify(ctx, db, tc, result); err != nil {
+				log.Printf("警告: %s 状态校验查询失败: %v", tc.Name, err) // EN: Warning: verification query failed
+			}
+		}
+	}
+
+	// 清理步骤总是执行，以免影响后续测试 // EN: Teardown always runs so later tests are not affected
+	if err := executeMongoSteps(ctx, db, tc.Collection, tc.Teardown); err != nil {
+		log.Printf("警告: %s Teardown 失败: %v", tc.Name, err) // EN: Warning: teardown failed
+	}
+	return result
+}
+
+// executeMongoTransaction 在 MongoDB 上执行事务测试；每个操作的结果（包括错误，例如预期的写冲突）记录在 result.Operations 中，
+// 操作的错误不会中断事务，返回的是事务本身的结果，事务后的状态由 Verify 记录。
+// 与运行器一样显式开启、提交和回滚事务，每个操作只执行一次
+// EN: executeMongoTransaction executes a transaction test against MongoDB; the result of every operation, errors included
+// EN: (such as an expected write conflict), is recorded in result.Operations. Operation errors do not interrupt the transaction,
+// EN: the returned error is the outcome of the transaction itself, and the resulting state is recorded through Verify.
+// EN: Like the runners it starts, commits and aborts the transaction explicitly, so every operation runs once.
+func executeMongoTransaction(ctx context.Context, db *mongo.Database, tc TestCase, result *ExpectedResult) error {
+	client := db.Client()
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	other, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer other.EndSession(ctx)
+
+	if err := session.StartTransaction(); err != nil {
+		return err
+	}
+	if err := other.StartTransaction(); err != nil {
+		session.AbortTransaction(ctx)
+		return err
+	}
+	txnCtx := mongo.NewSessionContext(ctx, session)
+	otherCtx := mongo.NewSessionContext(ctx, other)
+
+	for _, op := range tc.Transaction.Operations {
+		name := tc.Collection
+		if op.Collection != "" {
+			name = op.Collection
+		}
+		var opCtx context.Context = txnCtx
+		switch op.Session {
+		case SessionNone:
+			opCtx = ctx
+		case SessionOther:
+			opCtx = otherCtx
+		}
+		opResult := ExpectedResult{TestName: op.Action.Method, Source: reference.SourceMongoDB}
+		if err := executeMongoAction(opCtx, db, db.Collection(name), op.Action, &opResult); err != nil {
+			recordError(&opResult, err)
+		}
+		result.Operations = append(result.Operations, opResult)
+	}
+
+	// 冲突后并发事务可能已被服务端回滚，忽略回滚错误
+	// EN: The concurrent transaction may already have been aborted by the server after a conflict, so abort errors are ignored
+	other.AbortTransaction(ctx)
+	if tc.Transaction.Outcome == "abort" {
+		return session.AbortTransaction(ctx)
+	}
+	return session.CommitTransaction(ctx)
+}
+
+// executeMongoScenario 在 MongoDB 上依次执行场景步骤，替换对先前步骤结果的引用，每个步骤的结果记录在 result.Operations 中；
+// 与运行器一样，步骤出错时记录错误，不预期该错误时停止。无法解析的引用是用例本身的错误，不执行该步骤并返回错误
+// EN: executeMongoScenario executes the scenario steps in order against MongoDB, substituting references to earlier results,
+// EN: and records the result of every step in result.Operations. Like the runners it records the error of a failing step
+// EN: and stops unless the step expects that error. An unresolvable reference is a fault of the test case itself:
+// EN: the step is not executed and the error is returned.
+func executeMongoScenario(ctx context.Context, db *mongo.Database, tc TestCase, result *ExpectedResult) error {
+	var outputs []bson.D
+	for i, step := range tc.Steps {
+		name := tc.Collection
+		if step.Collection != "" {
+			name = step.Collection
+		}
+		action, err := resolveStepAction(step.Action, outputs)
+		if err != nil {
+			return fmt.Errorf("步骤 %d: %w", i, err) // EN: Step %d
+		}
+
+		stepResult := ExpectedResult{TestName: step.Action.Method, Source: reference.SourceMongoDB}
+		err = executeMongoAction(ctx, db, db.Collection(name), action, &stepResult)
+		if err != nil {
+			recordError(&stepResult, err)
+		}
+		result.Operations = append(result.Operations, stepResult)
+		outputs = append(outputs, stepOutputs(&stepResult))
+		if err != nil && step.Expected.Error == "" && step.Expected.ErrorCode == 0 && step.Expected.ErrorCodeName == "" {
+			log.Printf("警告: %s 步骤 %d 失败: %v", tc.Name, i, err) // EN: Warning: step %d failed
+			return nil
+		}
+	}
+	return nil
+}
+
+// resolveStepAction 替换步骤动作中的 $stepRef 引用，解析规则与运行器相同，返回遇到的第一个无法解析的引用
+// EN: resolveStepAction substitutes the $stepRef references in a step action with the same rules as the runners
+// EN: and returns the first unresolvable reference.
+func resolveStepAction(action TestAction, outputs []bson.D) (TestAction, error) {
+	var firstErr error
+	resolve := func(v any) any {
+		resolved, err := reference.ResolveStepRefs(v, outputs)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return resolved
+	}
+	action.Filter = resolve(action.Filter)
+	action.Update = resolve(action.Update)
+	action.Doc = resolve(action.Doc)
+	action.Options = resolve(action.Options)
+	if action.Docs != nil {
+		action.Docs, _ = resolve(action.Docs).([]any)
+	}
+	return action, firstErr
+}
+
+// stepOutputs 返回步骤结果中可被引用的字段
+// EN: stepOutputs returns the fields of a step result that can be referenced.
+func stepOutputs(result *ExpectedResult) bson.D {
+	docs := make([]any, len(result.Documents))
+	for i, d := range result.Documents {
+		docs[i] = d
+	}
+	out := bson.D{
+		{Key: "inserted_ids", Value: result.InsertedIDs},
+		{Key: "documents", Value: docs},
+		{Key: "count", Value: result.Count},
+		{Key: "matched_count", Value: result.MatchedCount},
+		{Key: "modified_count", Value: result.ModifiedCount},
+		{Key: "deleted_count", Value: result.DeletedCount},
+	}
+	if len(result.InsertedIDs) > 0 {
+		out = append(out, bson.E{Key: "inserted_id", Value: result.InsertedIDs[0]})
+	}
+	if result.UpsertedID != nil {
+		out = append(out, bson.E{Key: "upserted_id", Value: result.UpsertedID})
+	}
+	return out
+}
+
+// recordVerify 在动作之后执行状态校验查询，并记录返回的文档
+// EN: recordVerify runs the verification query after the action and records the returned documents.
+func recordVerify(ctx context.Context, db *mongo.Database, tc TestCase, result *ExpectedResult) error {
+	name := tc.Collection
+	if tc.Verify.Collection != "" {
+		name = tc.Verify.Collection
+	}
+	query := &ExpectedResult{}
+	action := TestAction{Method: "find", Filter: tc.Verify.Filter, Options: tc.Verify.Options}
+	if err := executeMongoAction(ctx, db, db.Collection(name), action, query); err != nil {
+		return err
+	}
+	result.VerifyDocuments = query.Documents
+	return nil
+}
+
+// executeMongoSteps 在 MongoDB 上执行前置或清理步骤
+// EN: executeMongoSteps executes setup or teardown steps against MongoDB.
+func executeMongoSteps(ctx context.Context, db *mongo.Database, collection string, steps []SetupStep) error {
+	for _, step := range steps {
+		name := collection
+		if step.Collection != "" {
+			name = step.Collection
+		}
+		col := db.Collection(name)
+
+		var err error
+		switch step.Operation {
+		case "insert":
+			_, err = col.InsertOne(ctx, step.Data)
+		case "insertMany":
+			docs, _ := step.Data.([]any)
+			_, err = col.InsertMany(ctx, docs)
+		case "deleteMany":
+			filter := step.Data
+			if filter == nil {
+				filter = bson.D{}
+			}
+			_, err = col.DeleteMany(ctx, filter)
+		case "createIndex":
+			_, err = col.Indexes().CreateOne(ctx, indexModel(step.Data))
+		case "dropIndex":
+			indexName, _ := field(step.Data, "name").(string)
+			_, err = col.Indexes().DropOne(ctx, indexName)
+		case "drop":
+			err = col.Drop(ctx)
+		case "createCollection":
+			err = db.CreateCollection(ctx, name)
+		default:
+			err = fmt.Errorf("未知步骤: %s", step.Operation) // EN: Unknown step
+		}
+		if err != nil {
+			return fmt.Errorf("%s 失败: %w", step.Operation, err) // EN: %s failed
+		}
+	}
+	return nil
+}
+
+// executeMongoAction 在 MongoDB 上执行测试动作
+// EN: executeMongoAction executes the test action against MongoDB.
+func executeMongoAction(ctx context.Context, db *mongo.Database, col *mongo.Collection, action TestAction, result *ExpectedResult) error {
+	filter := action.Filter
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	switch action.Method {
+	case "insertOne":
+		res, err := col.InsertOne(ctx, action.Doc)
+		if err != nil {
+			return err
+		}
+		result.Count = 1
+		result.InsertedIDs = []any{res.InsertedID}
+	case "insertMany":
+		res, err := col.InsertMany(ctx, action.Docs)
+		if err != nil {
+			return err
+		}
+		result.Count = int64(len(res.InsertedIDs))
+		result.InsertedIDs = res.InsertedIDs
+	case "find":
+		findOpts := options.Find()
+		if v := field(action.Options, "sort"); v != nil {
+			findOpts.SetSort(v)
+		}
+		if v := field(action.Options, "limit"); v != nil {
+			findOpts.SetLimit(toInt64(v))
+		}
+		if v := field(action.Options, "skip"); v != nil {
+			findOpts.SetSkip(toInt64(v))
+		}
+		if v := field(action.Options, "projection"); v != nil {
+			findOpts.SetProjection(v)
+		}
+		cursor, err := col.Find(ctx, filter, findOpts)
+		if err != nil {
+			return err
+		}
+		return decodeCursor(ctx, cursor, result)
+	case "findOne":
+		var doc bson.D
+		err := col.FindOne(ctx, filter).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result.Count = 1
+		result.Documents = []bson.D{doc}
+	case "updateOne", "updateMany":
+		updateOpts := options.Update()
+		if v, ok := field(action.Options, "upsert").(bool); ok {
+			updateOpts.SetUpsert(v)
+		}
+		if v, ok := field(action.Options, "arrayFilters").([]any); ok {
+			updateOpts.SetArrayFilters(options.ArrayFilters{Filters: v})
+		}
+		var res *mongo.UpdateResult
+		var err error
+		if action.Method == "updateOne" {
+			res, err = col.UpdateOne(ctx, filter, action.Update, updateOpts)
+		} else {
+			res, err = col.UpdateMany(ctx, filter, action.Update, updateOpts)
+		}
+		if err != nil {
+			return err
+		}
+		result.MatchedCount = res.MatchedCount
+		result.ModifiedCount = res.ModifiedCount
+		result.UpsertedID = res.UpsertedID
+	case "deleteOne", "deleteMany":
+		var res *mongo.DeleteResult
+		var err error
+		if action.Method == "deleteOne" {
+			res, err = col.DeleteOne(ctx, filter)
+		} else {
+			res, err = col.DeleteMany(ctx, filter)
+		}
+		if err != nil {
+			return err
+		}
+		result.DeletedCount = res.DeletedCount
+	case "replaceOne":
+		replaceOpts := options.Replace()
+		if v, ok := field(action.Options, "upsert").(bool); ok {
+			replaceOpts.SetUpsert(v)
+		}
+		res, err := col.ReplaceOne(ctx, filter, action.Doc, replaceOpts)
+		if err != nil {
+			return err
+		}
+		result.MatchedCount = res.MatchedCount
+		result.ModifiedCount = res.ModifiedCount
+		result.UpsertedID = res.UpsertedID
+	case "findAndModify":
+		// 使用 findAndModify 命令以保持与 MonoLite API 相同的语义
+		// EN: Use the findAndModify command to keep the same semantics as the MonoLite API
+		cmd := bson.D{{Key: "findAndModify", Value: col.Name()}, {Key: "query", Value: filter}}
+		if action.Update != nil {
+			cmd = append(cmd, bson.E{Key: "update", Value: action.Update})
+		}
+		for _, key := range []string{"new", "upsert", "remove"} {
+			if v := field(action.Options, key); v != nil {
+				cmd = append(cmd, bson.E{Key: key, Value: v})
+			}
+		}
+		var reply struct {
+			Value bson.D `bson:"value"`
+		}
+		if err := db.RunCommand(ctx, cmd).Decode(&reply); err != nil {
+			return err
+		}
+		if reply.Value != nil {
+			result.Count = 1
+			result.Documents = []bson.D{reply.Value}
+		}
+	case "distinct":
+		fieldName, _ := field(action.Options, "field").(string)
+		values, err := col.Distinct(ctx, fieldName, filter)
+		if err != nil {
+			return err
+		}
+		result.Count = int64(len(values))
+	case "aggregate":
+		cursor, err := col.Aggregate(ctx, field(action.Options, "pipeline"))
+		if err != nil {
+			return err
+		}
+		return decodeCursor(ctx, cursor, result)
+	case "createIndex":
+		name, err := col.Indexes().CreateOne(ctx, indexModel(action.Options))
+		if err != nil {
+			return err
+		}
+		result.Count = 1
+		result.IndexName = name
+		result.Documents = []bson.D{{{Key: "indexName", Value: name}}}
+	case "listIndexes":
+		cursor, err := col.Indexes().List(ctx)
+		if err != nil {
+			return err
+		}
+		var indexes []bson.D
+		if err := cursor.All(ctx, &indexes); err != nil {
+			return err
+		}
+		result.Count = int64(len(indexes))
+	case "dropIndex":
+		name, _ := field(action.Options, "name").(string)
+		if _, err := col.Indexes().DropOne(ctx, name); err != nil {
+			return err
+		}
+	case "findOneAndUpdate", "findOneAndReplace", "findOneAndDelete":
+		return executeMongoFindOneAnd(ctx, col, filter, action, result)
+	case "countDocuments":
+		countOpts := options.Count()
+		if v := field(action.Options, "skip"); v != nil {
+			countOpts.SetSkip(toInt64(v))
+		}
+		if v := field(action.Options, "limit"); v != nil {
+			countOpts.SetLimit(toInt64(v))
+		}
+		n, err := col.CountDocuments(ctx, filter, countOpts)
+		if err != nil {
+			return err
+		}
+		result.Count = n
+	case "estimatedDocumentCount":
+		n, err := col.EstimatedDocumentCount(ctx)
+		if err != nil {
+			return err
+		}
+		result.Count = n
+	case "bulkWrite":
+		return executeMongoBulkWrite(ctx, col, action, result)
+	case "drop":
+		return col.Drop(ctx)
+	case "listCollections":
+		names, err := db.ListCollectionNames(ctx, filter)
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+		result.Count = int64(len(names))
+		for _, name := range names {
+			result.Documents = append(result.Documents, bson.D{{Key: "name", Value: name}})
+		}
+	default:
+		return fmt.Errorf("未知方法: %s", action.Method) // EN: Unknown method
+	}
+	return nil
+}
+
+// executeMongoFindOneAnd 执行 findOneAndUpdate、findOneAndReplace 或 findOneAndDelete
+// EN: executeMongoFindOneAnd executes findOneAndUpdate, findOneAndReplace or findOneAndDelete.
+func executeMongoFindOneAnd(ctx context.Context, col *mongo.Collection, filter any, action TestAction, result *ExpectedResult) error {
+	returnDoc := options.Before
+	if field(action.Options, "returnDocument") == "after" {
+		returnDoc = options.After
+	}
+	upsert, _ := field(action.Options, "upsert").(bool)
+	sortSpec, projection := field(action.Options, "sort"), field(action.Options, "projection")
+
+	var res *mongo.SingleResult
+	switch action.Method {
+	case "findOneAndUpdate":
+		findOpts := options.FindOneAndUpdate().SetReturnDocument(returnDoc).SetUpsert(upsert)
+		if sortSpec != nil {
+			findOpts.SetSort(sortSpec)
+		}
+		if projection != nil {
+			findOpts.SetProjection(projection)
+		}
+		res = col.FindOneAndUpdate(ctx, filter, action.Update, findOpts)
+	case "findOneAndReplace":
+		findOpts := options.FindOneAndReplace().SetReturnDocument(returnDoc).SetUpsert(upsert)
+		if sortSpec != nil {
+			findOpts.SetSort(sortSpec)
+		}
+		if projection != nil {
+			findOpts.SetProjection(projection)
+		}
+		res = col.FindOneAndReplace(ctx, filter, action.Doc, findOpts)
+	default:
+		findOpts := options.FindOneAndDelete()
+		if sortSpec != nil {
+			findOpts.SetSort(sortSpec)
+		}
+		if projection != nil {
+			findOpts.SetProjection(projection)
+		}
+		res = col.FindOneAndDelete(ctx, filter, findOpts)
+	}
+
+	var doc bson.D
+	err := res.Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	result.Count = 1
+	result.Documents = []bson.D{doc}
+	return nil
+}
+
+// executeMongoBulkWrite 将批量写入操作转换为驱动的写入模型后执行；ordered 默认为真
+// EN: executeMongoBulkWrite converts the bulk write operations into driver write models and runs them; ordered defaults to true.
+func executeMongoBulkWrite(ctx context.Context, col *mongo.Collection, action TestAction, result *ExpectedResult) error {
+	ordered := true
+	if v, ok := field(action.Options, "ordered").(bool); ok {
+		ordered = v
+	}
+	ops, _ := field(action.Options, "operations").([]any)
+	models := make([]mongo.WriteModel, 0, len(ops))
+	for _, op := range ops {
+		model, err := bulkWriteModel(op)
+		if err != nil {
+			return err
+		}
+		models = append(models, model)
+	}
+
+	res, err := col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(ordered))
+	if res != nil {
+		result.Count = res.InsertedCount
+		result.MatchedCount = res.MatchedCount
+		result.ModifiedCount = res.ModifiedCount
+		result.DeletedCount = res.DeletedCount
+		// 按操作顺序取第一个 upsert 的 _id // EN: Take the _id of the first upsert in operation order
+		first := int64(-1)
+		for i, id := range res.UpsertedIDs {
+			if first < 0 || i < first {
+				first, result.UpsertedID = i, id
+			}
+		}
+	}
+	return err
+}
+
+// bulkWriteModel 将形如 {updateOne: {filter, update, upsert}} 的操作转换为驱动的写入模型
+// EN: bulkWriteModel converts an operation such as {updateOne: {filter, update, upsert}} into a driver write model.
+func bulkWriteModel(op any) (mongo.WriteModel, error) {
+	d, ok := op.(bson.D)
+	if !ok || len(d) != 1 {
+		return nil, fmt.Errorf("批量写入操作必须只有一个键: %v", op) // EN: A bulk write operation must have exactly one key
+	}
+	spec := d[0].Value
+	filter := field(spec, "filter")
+	if filter == nil {
+		filter = bson.D{}
+	}
+	upsert := field(spec, "upsert") == true
+	arrayFilters, _ := field(spec, "arrayFilters").([]any)
+
+	switch d[0].Key {
+	case "insertOne":
+		return mongo.NewInsertOneModel().SetDocument(field(spec, "document")), nil
+	case "updateOne":
+		model := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(field(spec, "update")).SetUpsert(upsert)
+		if arrayFilters != nil {
+			model.SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+		}
+		return model, nil
+	case "updateMany":
+		model := mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(field(spec, "update")).SetUpsert(upsert)
+		if arrayFilters != nil {
+			model.SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+		}
+		return model, nil
+	case "replaceOne":
+		return mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(field(spec, "replacement")).SetUpsert(upsert), nil
+	case "deleteOne":
+		return mongo.NewDeleteOneModel().SetFilter(filter), nil
+	case "deleteMany":
+		return mongo.NewDeleteManyModel().SetFilter(filter), nil
+	default:
+		return nil, fmt.Errorf("未知的批量写入操作: %s", d[0].Key) // EN: Unknown bulk write operation
+	}
+}
+
+// decodeCursor 读取游标中的全部文档
+// EN: decodeCursor reads all documents from a cursor.
+func decodeCursor(ctx context.Context, cursor *mongo.Cursor, result *ExpectedResult) error {
+	defer cursor.Close(ctx)
+	var docs []bson.D
+	if err := cursor.All(ctx, &docs); err != nil {
+		return err
+	}
+	result.Count = int64(len(docs))
+	result.Documents = docs
+	return nil
+}
+
+// indexModel 根据 {keys, options} 构造索引模型
+// EN: indexModel builds an index model from {keys, options}.
+func indexModel(spec any) mongo.IndexModel {
+	model := mongo.IndexModel{Keys: field(spec, "keys")}
+	indexOpts := field(spec, "options")
+	if indexOpts == nil {
+		return model
+	}
+	model.Options = options.Index()
+	if v, ok := field(indexOpts, "unique").(bool); ok {
+		model.Options.SetUnique(v)
+	}
+	if v, ok := field(indexOpts, "name").(string); ok {
+		model.Options.SetName(v)
+	}
+	return model
+}
+
+// recordError 记录错误信息和错误码
+// EN: recordError records the error message and error code.
+func recordError(result *ExpectedResult, err error) {
+	result.Error = err.Error()
+
+	var cmdErr mongo.CommandError
+	var writeErr mongo.WriteException
+	switch {
+	case errors.As(err, &cmdErr):
+		result.ErrorCode = cmdErr.Code
+		result.ErrorCodeName = cmdErr.Name
+	case errors.As(err, &writeErr) && len(writeErr.WriteErrors) > 0:
+		result.ErrorCode = int32(writeErr.WriteErrors[0].Code)
+	case errors.As(err, &writeErr) && writeErr.WriteConcernError != nil:
+		result.ErrorCode = int32(writeErr.WriteConcernError.Code)
+		result.ErrorCodeName = writeErr.WriteConcernError.Name
+	}
+}
+
+// field 读取文档字段（支持 map 和 bson.D）
+// EN: field reads a document field, supporting both maps and bson.D.
+func field(v any, key string) any {
+	switch d := v.(type) {
+	case map[string]any:
+		return d[key]
+	case bson.D:
+		for _, e := range d {
+			if e.Key == key {
+				return e.Value
+			}
+		}
+	}
+	return nil
+}
+
+// toInt64 转换为 int64
+// EN: toInt64 converts a value to int64.
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,132 @@
+// Created by Yanjunhui
+
+package main
+
+import "fmt"
+
+// GenerateScenarioTests 生成多步骤场景测试
+// 每个步骤都有自己的预期结果，后续步骤通过 stepRef 引用先前步骤的结果
+// EN: GenerateScenarioTests generates multi-step scenario tests.
+// EN: Every step has its own expected result, and later steps reference earlier results through stepRef.
+func GenerateScenarioTests() []TestCase {
+	fresh := []SetupStep{{Operation: "drop"}}
+	noID := doc("projection", doc("_id", 0))
+
+	return []TestCase{
+		scenarioTest("scenario_insert_update_find", "插入不带 _id 的文档，按生成的 _id 更新并查询", fresh, // EN: Insert a document without _id, then update and query it by the generated _id
+			[]ScenarioStep{
+				{Action: TestAction{Method: "insertOne", Doc: doc("name", "widget", "qty", 1)}, Expected: Expected{Count: intPtr(1)}},
+				{
+					Action:   TestAction{Method: "updateOne", Filter: doc("_id", stepRef(0, "inserted_id")), Update: doc("$inc", doc("qty", 4))},
+					Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+				},
+				{
+					Action: TestAction{Method: "findOne", Filter: doc("_id", stepRef(0, "inserted_id"))},
+					Expected: Expected{Count: intPtr(1), Documents: []any{
+						doc("_id", stepRef(0, "inserted_id"), "name", "widget", "qty", 5),
+					}},
+				},
+			},
+			&Verify{Filter: doc(), Options: noID, Documents: []any{doc("name", "widget", "qty", 5)}}),
+		scenarioTest("scenario_insert_many_delete_by_id", "批量插入后按返回的第二个 _id 删除", fresh, // EN: Insert many documents, then delete by the second returned _id
+			[]ScenarioStep{
+				{Action: TestAction{Method: "insertMany", Docs: []any{doc("n", 1), doc("n", 2), doc("n", 3)}}, Expected: Expected{Count: intPtr(3)}},
+				{Action: TestAction{Method: "deleteOne", Filter: doc("_id", stepRef(0, "inserted_ids.1"))}, Expected: Expected{DeletedCount: intPtr(1)}},
+				{
+					Action:   TestAction{Method: "find", Filter: doc(), Options: doc("sort", doc("n", 1), "projection", doc("_id", 0))},
+					Expected: Expected{Count: intPtr(2), Documents: []any{doc("n", 1), doc("n", 3)}},
+				},
+			},
+			nil),
+		scenarioTest("scenario_upsert_then_update", "upsert 插入后按 upserted_id 再次更新", fresh, // EN: Upsert a document, then update it again by upserted_id
+			[]ScenarioStep{
+				{
+					Action:   TestAction{Method: "updateOne", Filter: doc("sku", "A1"), Update: doc("$set", doc("qty", 1)), Options: doc("upsert", true)},
+					Expected: Expected{MatchedCount: intPtr(0), ModifiedCount: intPtr(0)},
+				},
+				{
+					Action:   TestAction{Method: "updateOne", Filter: doc("_id", stepRef(0, "upserted_id")), Update: doc("$inc", doc("qty", 1))},
+					Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+				},
+				{
+					Action:   TestAction{Method: "find", Filter: doc("sku", "A1"), Options: noID},
+					Expected: Expected{Count: intPtr(1), Documents: []any{doc("sku", "A1", "qty", 2)}},
+				},
+			},
+			nil),
+		scenarioTest("scenario_find_then_update", "查询第一个待处理任务，再按查询结果中的 _id 更新", // EN: Find the first pending task, then update it by the _id from the query result
+			append(append([]SetupStep{}, fresh...), SetupStep{Operation: "insertMany", Data: []any{
+				doc("_id", "s1", "status", "done"),
+				doc("_id", "s2", "status", "new"),
+				doc("_id", "s3", "status", "new"),
+			}}),
+			[]ScenarioStep{
+				{
+					Action:   TestAction{Method: "find", Filter: doc("status", "new"), Options: doc("sort", doc("_id", 1), "limit", 1)},
+					Expected: Expected{Count: intPtr(1), Documents: []any{doc("_id", "s2", "status", "new")}},
+				},
+				{
+					Action:   TestAction{Method: "updateOne", Filter: doc("_id", stepRef(0, "documents.0._id")), Update: doc("$set", doc("status", "done"))},
+					Expected: Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)},
+				},
+				{
+					Action:   TestAction{Method: "find", Filter: doc("status", "new"), Options: doc("sort", doc("_id", 1))},
+					Expected: Expected{Count: intPtr(1), Documents: []any{doc("_id", "s3", "status", "new")}},
+				},
+			},
+			&Verify{Filter: doc("status", "done"), Options: doc("sort", doc("_id", 1)), Documents: []any{
+				doc("_id", "s1", "status", "done"),
+				doc("_id", "s2", "status", "done"),
+			}}),
+		scenarioTest("scenario_counter_sequence", "用 findAndModify 维护计数器，并以计数值作为另一集合的 _id", // EN: Maintain a counter with findAndModify and use its value as the _id in another collection
+			append(append([]SetupStep{}, fresh...), SetupStep{Operation: "drop", Collection: "scenario_items"}),
+			[]ScenarioStep{
+				{
+					Action: TestAction{Method: "findAndModify", Filter: doc("_id", "seq"), Update: doc("$inc", doc("n", 1)),
+						Options: doc("new", true, "upsert", true)},
+					Expected: Expected{Count: intPtr(1), Documents: []any{doc("_id", "seq", "n", 1)}},
+				},
+				{
+					Action: TestAction{Method: "findAndModify", Filter: doc("_id", "seq"), Update: doc("$inc", doc("n", 1)),
+						Options: doc("new", true, "upsert", true)},
+					Expected: Expected{Count: intPtr(1), Documents: []any{doc("_id", "seq", "n", 2)}},
+				},
+				{
+					Collection: "scenario_items",
+					Action:     TestAction{Method: "insertOne", Doc: doc("_id", stepRef(1, "documents.0.n"), "label", "second")},
+					Expected:   Expected{Count: intPtr(1)},
+				},
+			},
+			&Verify{Collection: "scenario_items", Filter: doc(), Documents: []any{doc("_id", 2, "label", "second")}}),
+	}
+}
+
+// stepRef 辅助函数：构造引用第 step 个步骤结果的占位文档
+// EN: stepRef is a helper function that builds a placeholder referencing a result of the given step.
+func stepRef(step int, path string) any {
+	return doc("$stepRef", fmt.Sprintf("%d.%s", step, path))
+}
+
+// scenarioTest 构造场景测试，清理步骤删除测试用到的所有集合
+// EN: scenarioTest builds a scenario test whose teardown drops every collection the test used.
+func scenarioTest(name, description string, setup []SetupStep, steps []ScenarioStep, verify *Verify) TestCase {
+	teardown := []SetupStep{{Operation: "drop"}}
+	for _, step := range setup {
+		if step.Operation == "drop" && step.Collection != "" {
+			teardown = append(teardown, step)
+		}
+	}
+	return TestCase{
+		Name:        name,
+		Category:    "scenario",
+		Operation:   "steps",
+		Collection:  "scenario",
+		Description: description,
+		Setup:       setup,
+		Action:      TestAction{Method: "steps"},
+		Steps:       steps,
+		Comparison:  &Comparison{Ordered: true},
+		Verify:      verify,
+		Teardown:    teardown,
+	}
+}
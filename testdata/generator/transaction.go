@@ -2,12 +2,143 @@
 
 package main
 
-// GenerateTransactionTests 生成事务测试（简化版本）
-// EN: GenerateTransactionTests generates transaction test cases (simplified version).
+// GenerateTransactionTests 生成事务测试
+// 覆盖提交后可见、回滚、读己之写和写冲突，运行器按 Transaction 定义依次执行操作
+// EN: GenerateTransactionTests generates transaction test cases.
+// EN: They cover visibility after commit, rollback on abort, read-your-own-writes and write conflicts; runners execute the operations as the Transaction defines.
 func GenerateTransactionTests() []TestCase {
-	// 事务测试需要特殊处理，暂时返回空列表
-	// 因为事务需要在 runner 中特殊处理
-	// EN: Transaction tests require special handling, returning empty list for now.
-	// EN: Transactions need special handling in the runner.
-	return []TestCase{}
+	seed := []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: []any{
+			doc("_id", "t1", "v", 1),
+			doc("_id", "t2", "v", 2),
+		}},
+	}
+	byID := doc("sort", doc("_id", 1))
+
+	return []TestCase{
+		txnTest("txn_commit_visibility", "事务内插入的文档在提交前对事务外不可见，提交后可见", seed, // EN: A document inserted in a transaction is invisible outside it before commit and visible after
+			Transaction{
+				Operations: []TxnOperation{
+					txnOp(SessionTxn, TestAction{Method: "insertOne", Doc: doc("_id", "t3", "v", 3)}, Expected{Count: intPtr(1)}),
+					txnOp(SessionNone, TestAction{Method: "find", Filter: doc("_id", "t3")}, Expected{Count: intPtr(0)}),
+				},
+				Outcome: "commit",
+			},
+			&Verify{Filter: doc(), Options: byID, Documents: []any{
+				doc("_id", "t1", "v", 1),
+				doc("_id", "t2", "v", 2),
+				doc("_id", "t3", "v", 3),
+			}}),
+		txnTest("txn_commit_delete_visibility", "事务内删除的文档在提交前对事务外仍然可见", seed, // EN: A document deleted in a transaction stays visible outside it until commit
+			Transaction{
+				Operations: []TxnOperation{
+					txnOp(SessionTxn, TestAction{Method: "deleteOne", Filter: doc("_id", "t1")}, Expected{DeletedCount: intPtr(1)}),
+					txnOp(SessionNone, TestAction{Method: "find", Filter: doc("_id", "t1")}, Expected{Count: intPtr(1)}),
+				},
+				Outcome: "commit",
+			},
+			&Verify{Filter: doc(), Options: byID, Documents: []any{doc("_id", "t2", "v", 2)}}),
+		txnTest("txn_abort_rollback", "回滚后事务内的插入、更新和删除全部撤销", seed, // EN: Aborting undoes every insert, update and delete made in the transaction
+			Transaction{
+				Operations: []TxnOperation{
+					txnOp(SessionTxn, TestAction{Method: "insertOne", Doc: doc("_id", "t3", "v", 3)}, Expected{Count: intPtr(1)}),
+					txnOp(SessionTxn, TestAction{Method: "updateOne", Filter: doc("_id", "t1"), Update: doc("$set", doc("v", 100))},
+						Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)}),
+					txnOp(SessionTxn, TestAction{Method: "deleteOne", Filter: doc("_id", "t2")}, Expected{DeletedCount: intPtr(1)}),
+				},
+				Outcome: "abort",
+			},
+			&Verify{Filter: doc(), Options: byID, Documents: []any{
+				doc("_id", "t1", "v", 1),
+				doc("_id", "t2", "v", 2),
+			}}),
+		txnTest("txn_read_own_writes", "事务内的读取能看到本事务尚未提交的写入", seed, // EN: Reads inside a transaction see its own uncommitted writes
+			Transaction{
+				Operations: []TxnOperation{
+					txnOp(SessionTxn, TestAction{Method: "insertOne", Doc: doc("_id", "t3", "v", 3)}, Expected{Count: intPtr(1)}),
+					txnOp(SessionTxn, TestAction{Method: "updateOne", Filter: doc("_id", "t1"), Update: doc("$inc", doc("v", 10))},
+						Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)}),
+					txnOp(SessionTxn, TestAction{Method: "deleteOne", Filter: doc("_id", "t2")}, Expected{DeletedCount: intPtr(1)}),
+					txnOp(SessionTxn, TestAction{Method: "find", Filter: doc(), Options: byID}, Expected{
+						Count: intPtr(2),
+						Documents: []any{
+							doc("_id", "t1", "v", 11),
+							doc("_id", "t3", "v", 3),
+						},
+					}),
+					txnOp(SessionTxn, TestAction{Method: "aggregate", Options: doc("pipeline", []any{
+						doc("$group", doc("_id", nil, "total", doc("$sum", "$v"))),
+					})}, Expected{Count: intPtr(1), Documents: []any{doc("_id", nil, "total", 14)}}),
+				},
+				Outcome: "commit",
+			},
+			&Verify{Filter: doc(), Options: byID, Documents: []any{
+				doc("_id", "t1", "v", 11),
+				doc("_id", "t3", "v", 3),
+			}}),
+		txnTest("txn_write_conflict", "两个事务修改同一文档时，后写入的事务收到 WriteConflict 错误", seed, // EN: When two transactions modify the same document, the later writer gets a WriteConflict error
+			Transaction{
+				Operations: []TxnOperation{
+					txnOp(SessionTxn, TestAction{Method: "updateOne", Filter: doc("_id", "t1"), Update: doc("$set", doc("v", "first"))},
+						Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)}),
+					txnOp(SessionOther, TestAction{Method: "updateOne", Filter: doc("_id", "t1"), Update: doc("$set", doc("v", "second"))},
+						Expected{ErrorCode: 112, ErrorCodeName: "WriteConflict"}),
+				},
+				Outcome: "commit",
+			},
+			&Verify{Filter: doc("_id", "t1"), Documents: []any{doc("_id", "t1", "v", "first")}}),
+		txnTest("txn_multi_collection_commit", "跨集合的事务写入在提交时一起生效", // EN: Transactional writes to several collections take effect together on commit
+			append(append([]SetupStep{}, seed...),
+				SetupStep{Operation: "drop", Collection: "txn_audit"},
+				SetupStep{Operation: "createCollection", Collection: "txn_audit"},
+			),
+			Transaction{
+				Operations: []TxnOperation{
+					txnOp(SessionTxn, TestAction{Method: "updateOne", Filter: doc("_id", "t2"), Update: doc("$set", doc("v", 20))},
+						Expected{MatchedCount: intPtr(1), ModifiedCount: intPtr(1)}),
+					{Collection: "txn_audit", Action: TestAction{Method: "insertOne", Doc: doc("_id", "a1", "ref", "t2")}, Expected: Expected{Count: intPtr(1)}},
+					{Session: SessionNone, Collection: "txn_audit", Action: TestAction{Method: "find", Filter: doc()}, Expected: Expected{Count: intPtr(0)}},
+				},
+				Outcome: "commit",
+			},
+			&Verify{Collection: "txn_audit", Filter: doc(), Documents: []any{doc("_id", "a1", "ref", "t2")}}),
+	}
+}
+
+// 事务操作的执行位置 // EN: Where a transaction operation runs
+const (
+	SessionTxn   = "txn"   // 测试事务内 // EN: Inside the test transaction
+	SessionOther = "other" // 并发的另一个事务内 // EN: Inside a concurrent transaction
+	SessionNone  = "none"  // 事务外 // EN: Outside any transaction
+)
+
+// txnOp 辅助函数：构造在测试集合上执行的事务操作
+// EN: txnOp is a helper function that builds a transaction operation on the test collection.
+func txnOp(session string, action TestAction, expected Expected) TxnOperation {
+	return TxnOperation{Session: session, Action: action, Expected: expected}
+}
+
+// txnTest 构造事务测试，清理步骤删除测试用到的所有集合
+// EN: txnTest builds a transaction test whose teardown drops every collection the test used.
+func txnTest(name, description string, setup []SetupStep, txn Transaction, verify *Verify) TestCase {
+	teardown := []SetupStep{{Operation: "drop"}}
+	for _, step := range setup {
+		if step.Operation == "drop" && step.Collection != "" {
+			teardown = append(teardown, step)
+		}
+	}
+	return TestCase{
+		Name:        name,
+		Category:    "transaction",
+		Operation:   "transaction",
+		Collection:  "txn_test",
+		Description: description,
+		Setup:       setup,
+		Action:      TestAction{Method: "transaction"},
+		Transaction: &txn,
+		Comparison:  &Comparison{Ordered: true},
+		Verify:      verify,
+		Teardown:    teardown,
+	}
 }
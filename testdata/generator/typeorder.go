@@ -0,0 +1,248 @@
+// Created by Yanjunhui
+
+package main
+
+import (
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GenerateTypeOrderTests 生成跨类型比较与排序测试
+// MongoDB 对 BSON 类型定义了全序：
+// MinKey < null < 数值 < 字符串 < 对象 < 数组 < binData < ObjectId < bool < date < timestamp < regex < MaxKey
+// EN: GenerateTypeOrderTests generates cross-type comparison and sort order tests.
+// EN: MongoDB defines a total order across BSON types:
+// EN: MinKey < null < numbers < string < object < array < binData < ObjectId < bool < date < timestamp < regex < MaxKey
+func GenerateTypeOrderTests() []TestCase {
+	var tests []TestCase
+	tests = append(tests, generateTypeBracketTests()...)
+	tests = append(tests, generateArrayOrderTests()...)
+	tests = append(tests, generateNumericOrderTests()...)
+	tests = append(tests, generateNaNOrderTests()...)
+	return tests
+}
+
+// typeOrderDocs 返回按 BSON 类型顺序升序排列的文档，_id 的字典序与值的顺序一致
+// 数组在排序时按其元素参与比较，因此不放入此列表，由 generateArrayOrderTests 单独测试
+// EN: typeOrderDocs returns documents in ascending BSON type order; the lexical order of _id matches the value order.
+// EN: Arrays sort by their elements, so they are left out of this list and tested separately by generateArrayOrderTests.
+func typeOrderDocs() []any {
+	return []any{
+		doc("_id", "ord_01", "v", primitive.MinKey{}),
+		doc("_id", "ord_02", "v", nil),
+		doc("_id", "ord_03", "v", math.NaN()),
+		doc("_id", "ord_04", "v", int32(-5)),
+		doc("_id", "ord_05", "v", 1.5),
+		doc("_id", "ord_06", "v", int64(3)),
+		doc("_id", "ord_07", "v", mustDecimal("4.25")),
+		doc("_id", "ord_08", "v", "abc"),
+		doc("_id", "ord_09", "v", doc("a", 1)),
+		doc("_id", "ord_10", "v", primitive.Binary{Subtype: 0x00, Data: []byte("x")}),
+		doc("_id", "ord_11", "v", mustObjectID("65a1b2c3d4e5f60718293a4b")),
+		doc("_id", "ord_12", "v", false),
+		doc("_id", "ord_13", "v", true),
+		doc("_id", "ord_14", "v", dateValue(2024, 1, 1, 0, 0, 0, 0)),
+		doc("_id", "ord_15", "v", primitive.Timestamp{T: 1700000000, I: 1}),
+		doc("_id", "ord_16", "v", primitive.Regex{Pattern: "^a", Options: ""}),
+		doc("_id", "ord_17", "v", primitive.MaxKey{}),
+	}
+}
+
+// generateTypeBracketTests 生成跨类型排序和范围查询测试
+// EN: generateTypeBracketTests generates cross-type sort and range query tests.
+func generateTypeBracketTests() []TestCase {
+	ordered := typeOrderDocs()
+	setup := []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: shuffled(ordered)},
+	}
+	indexed := append(append([]SetupStep{}, setup...),
+		SetupStep{Operation: "createIndex", Data: doc("keys", doc("v", 1), "options", doc("name", "v_1"))})
+
+	return []TestCase{
+		typeOrderFind("type_order_sort_asc", "跨类型升序排序", "type_order", setup, // EN: Cross-type ascending sort
+			doc(), doc("v", 1), ordered),
+		typeOrderFind("type_order_sort_desc", "跨类型降序排序", "type_order", setup, // EN: Cross-type descending sort
+			doc(), doc("v", -1), reversed(ordered)),
+		{
+			Name:        "type_order_agg_sort",
+			Category:    "type_order",
+			Operation:   "$sort",
+			Collection:  "type_order",
+			Description: "聚合 $sort 跨类型排序", // EN: Cross-type sort in an aggregation $sort
+			Setup:       setup,
+			Action: TestAction{
+				Method:  "aggregate",
+				Options: doc("pipeline", []any{doc("$sort", doc("v", 1))}),
+			},
+			Expected:   Expected{Count: intPtr(int64(len(ordered))), Documents: ordered},
+			Comparison: &Comparison{Mode: "strict", Ordered: true},
+			Teardown:   []SetupStep{{Operation: "drop"}},
+		},
+		// 比较操作符只匹配同一类型区间的值 // EN: Comparison operators only match values in the same type bracket
+		typeOrderFind("type_order_gt_number", "$gt 数值只匹配数值类型，且不匹配 NaN", "type_order", setup, // EN: $gt on a number only matches numbers and never NaN
+			doc("v", doc("$gt", 1)), doc("v", 1), ordered[4:7]),
+		typeOrderFind("type_order_lt_string", "$lt 字符串只匹配字符串类型", "type_order", setup, // EN: $lt on a string only matches strings
+			doc("v", doc("$lt", "b")), doc("v", 1), ordered[7:8]),
+		typeOrderFind("type_order_lt_maxkey", "$lt MaxKey 匹配除 MaxKey 外的所有值", "type_order", setup, // EN: $lt MaxKey matches every value except MaxKey
+			doc("v", doc("$lt", primitive.MaxKey{})), doc("v", 1), ordered[:16]),
+		// 索引范围扫描 // EN: Index range scans
+		typeOrderFind("type_order_index_sort", "通过索引按跨类型顺序扫描", "type_order", indexed, // EN: Scan in cross-type order through an index
+			doc(), doc("v", 1), ordered),
+		typeOrderFind("type_order_index_range", "索引范围扫描跨越 int32、double、int64 与 Decimal128", "type_order", indexed, // EN: Index range scan across int32, double, int64 and Decimal128
+			doc("v", doc("$gte", int32(-5), "$lte", mustDecimal("4.25"))), doc("v", 1), ordered[3:7]),
+	}
+}
+
+// generateArrayOrderTests 生成数组值的排序和范围查询测试：
+// 升序排序按数组的最小元素、降序排序按最大元素参与比较，因此两个方向的预期顺序不是互为倒序；
+// 标量范围条件的每个边界可以由数组中不同的元素满足，数组操作数只与数组整体比较
+// EN: generateArrayOrderTests generates sort and range query tests on array values:
+// EN: an ascending sort compares an array by its smallest element and a descending sort by its largest,
+// EN: so the expected orders are not the reverse of each other; each bound of a scalar range can be met by a different element,
+// EN: while an array operand is compared with the array as a whole.
+func generateArrayOrderTests() []TestCase {
+	var (
+		scalar   = doc("_id", "arr_01", "v", int32(5))
+		wide     = doc("_id", "arr_02", "v", []any{int32(1), int32(9)})
+		narrow   = doc("_id", "arr_03", "v", []any{int32(3), int32(4)})
+		str      = doc("_id", "arr_04", "v", "m")
+		mixed    = doc("_id", "arr_05", "v", []any{int32(7), "z"})
+		object   = doc("_id", "arr_06", "v", doc("a", 1))
+		withBool = doc("_id", "arr_07", "v", []any{int32(2), true})
+	)
+	setup := []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: []any{scalar, wide, narrow, str, mixed, object, withBool}},
+	}
+
+	return []TestCase{
+		// 最小元素: 1 < 2 < 3 < 5 < 7 < "m" < 对象 // EN: Smallest elements: 1 < 2 < 3 < 5 < 7 < "m" < object
+		typeOrderFind("array_order_sort_asc", "数组升序排序按最小元素比较", "array_order", setup, // EN: An ascending sort compares arrays by their smallest element
+			doc(), doc("v", 1), []any{wide, withBool, narrow, scalar, mixed, str, object}),
+		// 最大元素: true > 对象 > "z" > "m" > 9 > 5 > 4 // EN: Largest elements: true > object > "z" > "m" > 9 > 5 > 4
+		typeOrderFind("array_order_sort_desc", "数组降序排序按最大元素比较", "array_order", setup, // EN: A descending sort compares arrays by their largest element
+			doc(), doc("v", -1), []any{withBool, object, mixed, str, wide, scalar, narrow}),
+		typeOrderFind("array_order_range_elements", "标量范围的两个边界可由数组中不同元素满足", "array_order", setup, // EN: The two bounds of a scalar range can be met by different array elements
+			doc("v", doc("$gte", int32(3), "$lte", int32(4))), doc("v", 1), []any{wide, narrow}),
+		typeOrderFind("array_order_range_array", "数组操作数只匹配整体更大的数组", "array_order", setup, // EN: An array operand only matches arrays that are greater as a whole
+			doc("v", doc("$gt", []any{int32(2)})), doc("v", 1), []any{withBool, narrow, mixed}),
+	}
+}
+
+// generateNumericOrderTests 生成数值跨类型比较测试
+// EN: generateNumericOrderTests generates cross-type numeric comparison tests.
+func generateNumericOrderTests() []TestCase {
+	equal := []any{
+		doc("_id", "num_01", "v", int32(1)),
+		doc("_id", "num_02", "v", int64(1)),
+		doc("_id", "num_03", "v", 1.0),
+		doc("_id", "num_04", "v", mustDecimal("1.0")),
+		doc("_id", "num_05", "v", mustDecimal("1.00")),
+	}
+	equalSetup := []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: append(append([]any{}, equal...),
+			doc("_id", "num_06", "v", int32(2)),
+			doc("_id", "num_07", "v", "1"),
+		)},
+	}
+
+	sorted := []any{
+		doc("_id", "num_11", "v", int64(-3)),
+		doc("_id", "num_12", "v", -0.5),
+		doc("_id", "num_13", "v", 2.5),
+		doc("_id", "num_14", "v", mustDecimal("7.125")),
+		doc("_id", "num_15", "v", int32(10)),
+		doc("_id", "num_16", "v", int64(1)<<40),
+	}
+	sortSetup := []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: shuffled(sorted)},
+	}
+
+	return []TestCase{
+		typeOrderFind("numeric_equal_cross_type", "int32、int64、double 与 Decimal128 的等值比较", "numeric_order", equalSetup, // EN: Equality across int32, int64, double and Decimal128
+			doc("v", 1), doc("_id", 1), equal),
+		typeOrderFind("numeric_equal_decimal", "Decimal128 过滤条件匹配等值的其他数值类型", "numeric_order", equalSetup, // EN: A Decimal128 filter matches equal values of other numeric types
+			doc("v", mustDecimal("1")), doc("_id", 1), equal),
+		typeOrderFind("numeric_sort_cross_type", "混合数值类型按数值大小排序", "numeric_order", sortSetup, // EN: Mixed numeric types sort by numeric value
+			doc(), doc("v", 1), sorted),
+		typeOrderFind("numeric_range_cross_type", "double 范围条件匹配整数与 Decimal128", "numeric_order", sortSetup, // EN: A double range matches integers and Decimal128
+			doc("v", doc("$gt", -0.5, "$lte", 10.0)), doc("v", 1), sorted[2:5]),
+	}
+}
+
+// generateNaNOrderTests 生成 NaN 的比较与排序测试
+// NaN 排在所有数值之前，但不满足任何范围比较，只与 NaN 相等
+// EN: generateNaNOrderTests generates NaN comparison and sort tests.
+// EN: NaN sorts before every other number but satisfies no range comparison and only equals NaN.
+func generateNaNOrderTests() []TestCase {
+	sorted := []any{
+		doc("_id", "nan_01", "v", nil),
+		doc("_id", "nan_02", "v", math.NaN()),
+		doc("_id", "nan_03", "v", math.Inf(-1)),
+		doc("_id", "nan_04", "v", int32(0)),
+		doc("_id", "nan_05", "v", math.Inf(1)),
+	}
+	setup := []SetupStep{
+		{Operation: "drop"},
+		{Operation: "insertMany", Data: shuffled(sorted)},
+	}
+
+	return []TestCase{
+		typeOrderFind("nan_sort", "NaN 排在 null 之后、所有数值之前", "nan_order", setup, // EN: NaN sorts after null and before every number
+			doc(), doc("v", 1), sorted),
+		typeOrderFind("nan_equality", "NaN 只与 NaN 相等", "nan_order", setup, // EN: NaN only equals NaN
+			doc("v", math.NaN()), doc("v", 1), sorted[1:2]),
+		typeOrderFind("nan_range_excluded", "范围比较不匹配 NaN", "nan_order", setup, // EN: Range comparisons never match NaN
+			doc("v", doc("$gte", math.Inf(-1))), doc("v", 1), sorted[2:]),
+		typeOrderFind("nan_lt_nan", "$lt NaN 不匹配任何文档", "nan_order", setup, // EN: $lt NaN matches no document
+			doc("v", doc("$lt", math.NaN())), doc("v", 1), nil),
+	}
+}
+
+// typeOrderFind 构造按排序条件查询并严格按顺序比较结果的测试
+// EN: typeOrderFind builds a test that queries with a sort and compares the results strictly in order.
+func typeOrderFind(name, description, collection string, setup []SetupStep, filter, sort any, expected []any) TestCase {
+	return TestCase{
+		Name:        name,
+		Category:    "type_order",
+		Operation:   "find",
+		Collection:  collection,
+		Description: description,
+		Setup:       setup,
+		Action: TestAction{
+			Method:  "find",
+			Filter:  filter,
+			Options: doc("sort", sort),
+		},
+		Expected:   Expected{Count: intPtr(int64(len(expected))), Documents: expected},
+		Comparison: &Comparison{Mode: "strict", Ordered: true},
+		Teardown:   []SetupStep{{Operation: "drop"}},
+	}
+}
+
+// shuffled 辅助函数：以固定的交错顺序返回文档，保证插入顺序与排序结果不同
+// EN: shuffled is a helper function that returns documents in a fixed interleaved order so insertion order differs from the sorted order.
+func shuffled(docs []any) []any {
+	out := make([]any, 0, len(docs))
+	for i := len(docs) - 1; i >= 0; i -= 2 {
+		out = append(out, docs[i])
+	}
+	for i := len(docs) % 2; i < len(docs); i += 2 {
+		out = append(out, docs[i])
+	}
+	return out
+}
+
+// reversed 辅助函数：返回倒序的文档列表
+// EN: reversed is a helper function that returns the documents in reverse order.
+func reversed(docs []any) []any {
+	out := make([]any, len(docs))
+	for i, d := range docs {
+		out[len(docs)-1-i] = d
+	}
+	return out
+}
@@ -2,48 +2,126 @@
 
 package main
 
+import "go.mongodb.org/mongo-driver/bson"
+
 // TestCase 测试用例定义
 // EN: TestCase defines a test case structure.
 type TestCase struct {
-	Name        string      `json:"name"`        // 测试名称 // EN: Test name
-	Category    string      `json:"category"`    // 分类: crud, update_op, query_op, aggregate, index, transaction // EN: Category: crud, update_op, query_op, aggregate, index, transaction
-	Operation   string      `json:"operation"`   // 操作类型 // EN: Operation type
-	Collection  string      `json:"collection"`  // 集合名称 // EN: Collection name
-	Description string      `json:"description"` // 描述 // EN: Description
-	Setup       []SetupStep `json:"setup"`       // 前置步骤 // EN: Setup steps
-	Action      TestAction  `json:"action"`      // 测试动作 // EN: Test action
-	Expected    Expected    `json:"expected"`    // 预期结果 // EN: Expected result
+	Name        string      `json:"name" bson:"name"`                                 // 测试名称 // EN: Test name
+	Category    string      `json:"category" bson:"category"`                         // 分类: crud, update_op, query_op, aggregate, index, transaction, bson_types, type_order, expression, scenario // EN: Category: crud, update_op, query_op, aggregate, index, transaction, bson_types, type_order, expression, scenario
+	Operation   string      `json:"operation" bson:"operation"`                       // 操作类型 // EN: Operation type
+	Collection  string      `json:"collection" bson:"collection"`                     // 集合名称 // EN: Collection name
+	Description string      `json:"description" bson:"description"`                   // 描述 // EN: Description
+	Setup       []SetupStep `json:"setup" bson:"setup"`                               // 前置步骤 // EN: Setup steps
+	Action      TestAction  `json:"action" bson:"action"`                             // 测试动作 // EN: Test action
+	Teardown    []SetupStep `json:"teardown,omitempty" bson:"teardown,omitempty"`     // 清理步骤（无论结果如何都会执行）// EN: Teardown steps (always executed regardless of the outcome)
+	Expected    Expected    `json:"expected" bson:"expected"`                         // 预期结果 // EN: Expected result
+	Comparison  *Comparison `json:"comparison,omitempty" bson:"comparison,omitempty"` // 文档比较配置 // EN: Document comparison settings
+	TimeoutMS   int64       `json:"timeout_ms,omitempty" bson:"timeout_ms,omitempty"` // 超时时间（毫秒），覆盖运行器的 --timeout // EN: Timeout in milliseconds, overrides the runner's --timeout
+	Verify      *Verify     `json:"verify,omitempty" bson:"verify,omitempty"`         // 动作后的状态校验 // EN: Post-action state verification
+
+	Transaction *Transaction   `json:"transaction,omitempty" bson:"transaction,omitempty"` // 事务定义（动作方法为 transaction 时使用）// EN: Transaction definition (used when the action method is transaction)
+	Steps       []ScenarioStep `json:"steps,omitempty" bson:"steps,omitempty"`             // 场景步骤（动作方法为 steps 时使用）// EN: Scenario steps (used when the action method is steps)
+}
+
+// Comparison 文档比较配置
+// EN: Comparison configures how expected documents are compared with returned documents.
+type Comparison struct {
+	Mode    string `json:"mode,omitempty" bson:"mode,omitempty"`       // strict（类型精确、字段有序）或 lenient（默认）// EN: strict (exact types, ordered fields) or lenient (default)
+	Ordered bool   `json:"ordered,omitempty" bson:"ordered,omitempty"` // 是否按顺序比较结果集 // EN: Whether the result set is compared in order
+}
+
+// Verify 动作执行后的状态校验：执行一次查询并比较返回的文档，
+// 比较方式与 Expected.Documents 相同（遵循 Comparison）；
+// 记录参考结果时也会在 MongoDB 上执行该查询
+// EN: Verify checks the state after the action by running a query and comparing the returned documents,
+// EN: in the same way as Expected.Documents (following Comparison);
+// EN: the query also runs against MongoDB when reference results are recorded.
+type Verify struct {
+	Collection string `json:"collection,omitempty" bson:"collection,omitempty"` // 查询的集合，默认为测试集合 // EN: Collection to query, defaults to the test collection
+	Filter     any    `json:"filter,omitempty" bson:"filter,omitempty"`         // 查询条件 // EN: Query filter
+	Options    any    `json:"options,omitempty" bson:"options,omitempty"`       // 查询选项（sort、projection 等）// EN: Query options (sort, projection, ...)
+	Documents  []any  `json:"documents" bson:"documents"`                       // 预期文档 // EN: Expected documents
+}
+
+// Transaction 事务测试：开启事务，依次执行操作，最后按 Outcome 提交（commit）或回滚（abort）；
+// 每个操作都有自己的预期结果，事务结束后的状态通过 Verify 校验
+// EN: Transaction describes a transaction test: start a transaction, run the operations in order, then commit or abort according to Outcome;
+// EN: every operation has its own expected result, and the state after the transaction is checked through Verify.
+type Transaction struct {
+	Operations []TxnOperation `json:"operations" bson:"operations"` // 操作列表 // EN: Operations
+	Outcome    string         `json:"outcome" bson:"outcome"`       // commit 或 abort // EN: commit or abort
+}
+
+// TxnOperation 事务测试中的单个操作及其预期结果
+// EN: TxnOperation is a single operation of a transaction test with its expected result.
+type TxnOperation struct {
+	Session    string     `json:"session,omitempty" bson:"session,omitempty"`       // 执行位置：txn（默认）、other（并发的另一个事务）、none（事务外）// EN: Where it runs: txn (default), other (a concurrent transaction), none (outside any transaction)
+	Collection string     `json:"collection,omitempty" bson:"collection,omitempty"` // 目标集合，默认为测试集合 // EN: Target collection, defaults to the test collection
+	Action     TestAction `json:"action" bson:"action"`                             // 操作 // EN: Operation
+	Expected   Expected   `json:"expected" bson:"expected"`                         // 预期结果 // EN: Expected result
+}
+
+// ScenarioStep 场景测试中的单个步骤，按顺序执行，各自检查预期结果，在第一个失败的步骤处停止；
+// 动作和预期结果中的 {"$stepRef": "<步骤序号>.<路径>"} 会被替换为先前步骤的结果，
+// 可引用的路径：inserted_id、inserted_ids、upserted_id、count、matched_count、modified_count、deleted_count、documents
+// EN: ScenarioStep is a single step of a scenario test; steps run in order, each checks its own expected result, and execution stops at the first failure.
+// EN: {"$stepRef": "<step index>.<path>"} in the action and expected result is replaced with a result of an earlier step;
+// EN: referenceable paths: inserted_id, inserted_ids, upserted_id, count, matched_count, modified_count, deleted_count, documents
+type ScenarioStep struct {
+	Name       string     `json:"name,omitempty" bson:"name,omitempty"`             // 步骤名称 // EN: Step name
+	Collection string     `json:"collection,omitempty" bson:"collection,omitempty"` // 目标集合，默认为测试集合 // EN: Target collection, defaults to the test collection
+	Action     TestAction `json:"action" bson:"action"`                             // 动作 // EN: Action
+	Expected   Expected   `json:"expected" bson:"expected"`                         // 预期结果 // EN: Expected result
 }
 
-// SetupStep 测试前置步骤
-// EN: SetupStep defines a setup step before test execution.
+// SetupStep 测试前置或清理步骤
+// 支持的操作及其 Data：
+//   - insert: 单个文档
+//   - insertMany: 文档列表
+//   - deleteMany: 过滤条件（为空时删除全部）
+//   - createIndex: {keys, options}
+//   - dropIndex: {name}
+//   - drop / createCollection: 无
+//
+// EN: SetupStep defines a setup or teardown step around test execution.
+// EN: Supported operations and their Data:
+// EN:   - insert: a single document
+// EN:   - insertMany: a list of documents
+// EN:   - deleteMany: a filter (empty deletes everything)
+// EN:   - createIndex: {keys, options}
+// EN:   - dropIndex: {name}
+// EN:   - drop / createCollection: none
 type SetupStep struct {
-	Operation string `json:"operation"` // insert, createIndex, etc. // EN: insert, createIndex, etc.
-	Data      any    `json:"data"`      // 操作数据 // EN: Operation data
+	Operation  string `json:"operation" bson:"operation"`                       // 操作类型 // EN: Operation type
+	Collection string `json:"collection,omitempty" bson:"collection,omitempty"` // 目标集合，默认为测试集合 // EN: Target collection, defaults to the test collection
+	Data       any    `json:"data,omitempty" bson:"data,omitempty"`             // 操作数据 // EN: Operation data
 }
 
 // TestAction 测试动作
 // EN: TestAction defines the action to be performed in a test.
 type TestAction struct {
-	Method  string `json:"method"`            // 方法名: find, insert, update, delete, aggregate, etc. // EN: Method name: find, insert, update, delete, aggregate, etc.
-	Filter  any    `json:"filter,omitempty"`  // 查询条件 // EN: Query filter
-	Update  any    `json:"update,omitempty"`  // 更新内容 // EN: Update content
-	Doc     any    `json:"doc,omitempty"`     // 文档 // EN: Document
-	Docs    []any  `json:"docs,omitempty"`    // 文档列表 // EN: Document list
-	Options any    `json:"options,omitempty"` // 选项 // EN: Options
+	Method  string `json:"method" bson:"method"`                       // 方法名: find, insert, update, delete, aggregate, etc. // EN: Method name: find, insert, update, delete, aggregate, etc.
+	Filter  any    `json:"filter,omitempty" bson:"filter,omitempty"`   // 查询条件 // EN: Query filter
+	Update  any    `json:"update,omitempty" bson:"update,omitempty"`   // 更新内容 // EN: Update content
+	Doc     any    `json:"doc,omitempty" bson:"doc,omitempty"`         // 文档 // EN: Document
+	Docs    []any  `json:"docs,omitempty" bson:"docs,omitempty"`       // 文档列表 // EN: Document list
+	Options any    `json:"options,omitempty" bson:"options,omitempty"` // 选项 // EN: Options
 }
 
 // Expected 预期结果
 // EN: Expected defines the expected result of a test.
 type Expected struct {
-	Count         *int64 `json:"count,omitempty"`          // 预期数量 // EN: Expected count
-	Documents     []any  `json:"documents,omitempty"`      // 预期文档 // EN: Expected documents
-	MatchedCount  *int64 `json:"matched_count,omitempty"`  // 匹配数量 // EN: Matched count
-	ModifiedCount *int64 `json:"modified_count,omitempty"` // 修改数量 // EN: Modified count
-	DeletedCount  *int64 `json:"deleted_count,omitempty"`  // 删除数量 // EN: Deleted count
-	UpsertedID    any    `json:"upserted_id,omitempty"`    // Upsert ID // EN: Upserted ID
-	Error         string `json:"error,omitempty"`          // 预期错误 // EN: Expected error
-	IndexName     string `json:"index_name,omitempty"`     // 索引名称 // EN: Index name
+	Count         *int64 `json:"count,omitempty" bson:"count,omitempty"`                     // 预期数量 // EN: Expected count
+	Documents     []any  `json:"documents,omitempty" bson:"documents,omitempty"`             // 预期文档 // EN: Expected documents
+	MatchedCount  *int64 `json:"matched_count,omitempty" bson:"matched_count,omitempty"`     // 匹配数量 // EN: Matched count
+	ModifiedCount *int64 `json:"modified_count,omitempty" bson:"modified_count,omitempty"`   // 修改数量 // EN: Modified count
+	DeletedCount  *int64 `json:"deleted_count,omitempty" bson:"deleted_count,omitempty"`     // 删除数量 // EN: Deleted count
+	UpsertedID    any    `json:"upserted_id,omitempty" bson:"upserted_id,omitempty"`         // Upsert ID // EN: Upserted ID
+	Error         string `json:"error,omitempty" bson:"error,omitempty"`                     // 预期错误信息中包含的文本，没有错误码时使用 // EN: Text the expected error message contains, used when no error code is given
+	ErrorCode     int32  `json:"error_code,omitempty" bson:"error_code,omitempty"`           // 预期错误码 // EN: Expected error code
+	ErrorCodeName string `json:"error_code_name,omitempty" bson:"error_code_name,omitempty"` // 预期错误码名称 // EN: Expected error code name
+	IndexName     string `json:"index_name,omitempty" bson:"index_name,omitempty"`           // 索引名称 // EN: Index name
 }
 
 // TestResult 测试结果
@@ -66,9 +144,9 @@ type TestResult struct {
 // TestSuite 测试套件
 // EN: TestSuite defines a collection of test cases.
 type TestSuite struct {
-	Version   string     `json:"version"`
-	Generated string     `json:"generated"`
-	Tests     []TestCase `json:"tests"`
+	Version   string     `json:"version" bson:"version"`
+	Generated string     `json:"generated" bson:"generated"`
+	Tests     []TestCase `json:"tests" bson:"tests"`
 }
 
 // intPtr 辅助函数：创建 int64 指针
@@ -77,14 +155,14 @@ func intPtr(i int64) *int64 {
 	return &i
 }
 
-// doc 辅助函数：将 key-value 对转换为 map
-// EN: doc is a helper function to convert key-value pairs to a map.
-func doc(pairs ...any) map[string]any {
-	m := make(map[string]any)
+// doc 辅助函数：将 key-value 对转换为保持字段顺序的 bson.D
+// EN: doc is a helper function to convert key-value pairs to an order-preserving bson.D.
+func doc(pairs ...any) bson.D {
+	d := bson.D{}
 	for i := 0; i+1 < len(pairs); i += 2 {
 		if key, ok := pairs[i].(string); ok {
-			m[key] = pairs[i+1]
+			d = append(d, bson.E{Key: key, Value: pairs[i+1]})
 		}
 	}
-	return m
+	return d
 }
@@ -0,0 +1,7 @@
+// Created by Yanjunhui
+
+module github.com/monolite/monolite-test/reference
+
+go 1.21
+
+require go.mongodb.org/mongo-driver v1.17.6
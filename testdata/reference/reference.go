@@ -0,0 +1,59 @@
+// Created by Yanjunhui
+
+// Package reference 定义生成器和运行器共用的参考结果格式，以及场景步骤引用的解析
+// EN: Package reference defines the reference result format shared by the generator and the runners, and resolves scenario step references.
+package reference
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 参考结果来源 // EN: Reference result sources
+const (
+	SourceMongoDB = "mongodb" // 生成器在真实 MongoDB 上记录 // EN: Recorded by the generator against a real MongoDB
+	SourceGolden  = "golden"  // 运行器以 --update-golden 记录的 MonoLite 结果 // EN: MonoLite results recorded by the runner with --update-golden
+)
+
+// ExpectedResult 参考结果（MongoDB 记录或黄金文件）
+// EN: ExpectedResult is a reference result, either recorded from MongoDB or a golden file.
+type ExpectedResult struct {
+	TestName        string   `bson:"test_name"`                  // 测试名称 // EN: Test name
+	Source          string   `bson:"source"`                     // 结果来源 // EN: Result source
+	Documents       []bson.D `bson:"documents,omitempty"`        // 返回的文档 // EN: Returned documents
+	Count           int64    `bson:"count"`                      // 数量 // EN: Count
+	MatchedCount    int64    `bson:"matched_count"`              // 匹配数量 // EN: Matched count
+	ModifiedCount   int64    `bson:"modified_count"`             // 修改数量 // EN: Modified count
+	DeletedCount    int64    `bson:"deleted_count"`              // 删除数量 // EN: Deleted count
+	UpsertedID      any      `bson:"upserted_id,omitempty"`      // Upsert ID // EN: Upserted ID
+	IndexName       string   `bson:"index_name,omitempty"`       // 索引名称 // EN: Index name
+	VerifyDocuments []bson.D `bson:"verify_documents,omitempty"` // 状态校验查询返回的文档 // EN: Documents returned by the verification query
+	InsertedIDs     []any    `bson:"-"`                          // 插入文档的 _id，仅供生成器的场景步骤引用 // EN: _id values of the inserted documents, only used by the generator's scenario step references
+	Error           string   `bson:"error,omitempty"`            // 错误信息 // EN: Error message
+	ErrorCode       int32    `bson:"error_code,omitempty"`       // 错误码 // EN: Error code
+	ErrorCodeName   string   `bson:"error_code_name,omitempty"`  // 错误码名称 // EN: Error code name
+
+	Operations []ExpectedResult `bson:"operations,omitempty"` // 事务操作或场景步骤的结果 // EN: Results of the transaction operations or scenario steps
+}
+
+// Path 返回测试用例对应的参考结果文件路径
+// EN: Path returns the reference result file path of a test case.
+func Path(dir, testName string) string {
+	return filepath.Join(dir, testName+".json")
+}
+
+// Read 读取 Extended JSON 格式的参考结果
+// EN: Read reads a reference result in Extended JSON format.
+func Read(path string) (*ExpectedResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ref ExpectedResult
+	if err := bson.UnmarshalExtJSON(data, false, &ref); err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
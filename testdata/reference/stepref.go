@@ -0,0 +1,108 @@
+// Created by Yanjunhui
+
+package reference
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// StepRefKey 引用先前步骤结果的占位文档的键，形如 {"$stepRef": "0.inserted_id"}
+// EN: StepRefKey is the key of a placeholder document referencing the result of an earlier step, e.g. {"$stepRef": "0.inserted_id"}.
+const StepRefKey = "$stepRef"
+
+// ResolveStepRefs 递归替换值中的 $stepRef 引用，outputs 为已执行步骤可被引用的字段；
+// 原值不会被修改，返回遇到的第一个无法解析的引用
+// EN: ResolveStepRefs substitutes the $stepRef references in a value recursively; outputs holds the referenceable fields of the executed steps.
+// EN: The original value is left untouched, and the first unresolvable reference is returned as an error.
+func ResolveStepRefs(v any, outputs []bson.D) (any, error) {
+	r := &stepResolver{outputs: outputs}
+	resolved := r.resolve(v)
+	return resolved, r.err
+}
+
+// stepResolver 递归替换引用，记录遇到的第一个错误
+// EN: stepResolver substitutes references recursively and keeps the first error it meets.
+type stepResolver struct {
+	outputs []bson.D // 已执行步骤可被引用的字段 // EN: Referenceable fields of the executed steps
+	err     error    // 第一个解析错误 // EN: First resolution error
+}
+
+// resolve 返回替换引用后的值，数组保持原有的类型
+// EN: resolve returns the value with references substituted; arrays keep their original type.
+func (r *stepResolver) resolve(v any) any {
+	switch val := v.(type) {
+	case bson.D:
+		if len(val) == 1 && val[0].Key == StepRefKey {
+			ref, _ := val[0].Value.(string)
+			return r.lookup(ref)
+		}
+		out := make(bson.D, len(val))
+		for i, e := range val {
+			out[i] = bson.E{Key: e.Key, Value: r.resolve(e.Value)}
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(val))
+		for i, item := range val {
+			out[i] = r.resolve(item)
+		}
+		return out
+	case []any:
+		return []any(r.resolve(bson.A(val)).(bson.A))
+	default:
+		return v
+	}
+}
+
+// lookup 解析形如 "<步骤序号>.<路径>" 的引用
+// EN: lookup resolves a reference of the form "<step index>.<path>".
+func (r *stepResolver) lookup(ref string) any {
+	index, path, _ := strings.Cut(ref, ".")
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(r.outputs) {
+		r.fail(fmt.Errorf("无效的步骤引用 %q: 只能引用已执行的步骤", ref)) // EN: Invalid step reference %q: only executed steps can be referenced
+		return nil
+	}
+	value, ok := LookupPath(r.outputs[i], path)
+	if !ok {
+		r.fail(fmt.Errorf("步骤引用 %q 不存在", ref)) // EN: Step reference %q does not exist
+		return nil
+	}
+	return value
+}
+
+// fail 记录第一个解析错误
+// EN: fail records the first resolution error.
+func (r *stepResolver) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// LookupPath 按点分路径读取嵌套值，数组段使用数字下标；路径不存在时返回 false
+// EN: LookupPath reads a nested value by a dotted path, with numeric indexes for array segments; it returns false when the path does not exist.
+func LookupPath(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	head, rest, _ := strings.Cut(path, ".")
+	switch val := v.(type) {
+	case bson.D:
+		for _, e := range val {
+			if e.Key == head {
+				return LookupPath(e.Value, rest)
+			}
+		}
+	case bson.A:
+		if i, err := strconv.Atoi(head); err == nil && i >= 0 && i < len(val) {
+			return LookupPath(val[i], rest)
+		}
+	case []any:
+		return LookupPath(bson.A(val), path)
+	}
+	return nil, false
+}
@@ -0,0 +1,139 @@
+// Created by Yanjunhui
+
+package reference
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLookupPath(t *testing.T) {
+	output := bson.D{
+		{Key: "inserted_id", Value: "a1"},
+		{Key: "inserted_ids", Value: []any{"a1", "a2"}},
+		{Key: "documents", Value: bson.A{
+			bson.D{{Key: "_id", Value: "a1"}, {Key: "tags", Value: bson.A{"x", "y"}}},
+		}},
+		{Key: "count", Value: int64(0)},
+		{Key: "missing_value", Value: nil},
+	}
+
+	tests := []struct {
+		path  string
+		want  any
+		found bool
+	}{
+		{"", output, true},
+		{"inserted_id", "a1", true},
+		{"inserted_ids.1", "a2", true},
+		{"documents.0._id", "a1", true},
+		{"documents.0.tags.1", "y", true},
+		{"count", int64(0), true},
+		{"missing_value", nil, true},
+		{"inserted_ids.2", nil, false},
+		{"inserted_ids.-1", nil, false},
+		{"inserted_ids.x", nil, false},
+		{"documents.0.name", nil, false},
+		{"inserted_id.0", nil, false},
+		{"unknown", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, found := LookupPath(output, tt.path)
+		if found != tt.found || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LookupPath(%q) = %v, %v; want %v, %v", tt.path, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestResolveStepRefs(t *testing.T) {
+	outputs := []bson.D{
+		{{Key: "inserted_id", Value: "a1"}},
+		{{Key: "documents", Value: []any{bson.D{{Key: "n", Value: int32(5)}}}}},
+	}
+	ref := func(path string) bson.D { return bson.D{{Key: StepRefKey, Value: path}} }
+
+	tests := []struct {
+		name    string
+		in      any
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "no references",
+			in:   bson.D{{Key: "a", Value: int32(1)}},
+			want: bson.D{{Key: "a", Value: int32(1)}},
+		},
+		{
+			name: "top level reference",
+			in:   ref("0.inserted_id"),
+			want: "a1",
+		},
+		{
+			name: "nested in document and array",
+			in:   bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{ref("0.inserted_id"), "b"}}}}},
+			want: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{"a1", "b"}}}}},
+		},
+		{
+			name: "slice keeps its type",
+			in:   []any{ref("1.documents.0.n")},
+			want: []any{int32(5)},
+		},
+		{
+			name: "document with extra keys is not a reference",
+			in:   bson.D{{Key: StepRefKey, Value: "0.inserted_id"}, {Key: "x", Value: int32(1)}},
+			want: bson.D{{Key: StepRefKey, Value: "0.inserted_id"}, {Key: "x", Value: int32(1)}},
+		},
+		{
+			name:    "step not executed yet",
+			in:      ref("2.inserted_id"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid step index",
+			in:      ref("first.inserted_id"),
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			in:      bson.D{{Key: "a", Value: ref("0.upserted_id")}},
+			want:    bson.D{{Key: "a", Value: nil}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveStepRefs(tt.in, outputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveStepRefs = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveStepRefsKeepsFirstError(t *testing.T) {
+	in := bson.A{
+		bson.D{{Key: StepRefKey, Value: "0.first"}},
+		bson.D{{Key: StepRefKey, Value: "0.second"}},
+	}
+	_, err := ResolveStepRefs(in, []bson.D{{}})
+	if err == nil || err.Error() != `步骤引用 "0.first" 不存在` {
+		t.Errorf("error = %v, want the reference to 0.first", err)
+	}
+}
+
+func TestResolveStepRefsLeavesInputUntouched(t *testing.T) {
+	in := bson.D{{Key: "a", Value: bson.D{{Key: StepRefKey, Value: "0.inserted_id"}}}}
+	if _, err := ResolveStepRefs(in, []bson.D{{{Key: "inserted_id", Value: "a1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := in[0].Value.(bson.D); !ok {
+		t.Errorf("input was modified: %v", in)
+	}
+}
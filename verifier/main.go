@@ -65,12 +65,26 @@ type TestResult struct {
 	Language      string `json:"language"`                // 语言 // EN: Language
 	Mode          string `json:"mode"`                    // 模式 // EN: Mode
 	Success       bool   `json:"success"`                 // 是否成功 // EN: Success status
+	Status        string `json:"status,omitempty"`        // 状态: passed, failed, skipped, timeout // EN: Status: passed, failed, skipped, timeout
+	SkipReason    string `json:"skip_reason,omitempty"`   // 跳过原因 // EN: Skip reason
 	Error         string `json:"error,omitempty"`         // 错误信息 // EN: Error message
 	Duration      int64  `json:"duration_ms"`             // 耗时（毫秒）// EN: Duration in milliseconds
 	Count         int64  `json:"count,omitempty"`         // 数量 // EN: Count
 	MatchedCount  int64  `json:"matched_count,omitempty"` // 匹配数量 // EN: Matched count
 	ModifiedCount int64  `json:"modified_count,omitempty"` // 修改数量 // EN: Modified count
 	DeletedCount  int64  `json:"deleted_count,omitempty"` // 删除数量 // EN: Deleted count
+
+	AssertionFailures []AssertionFailure `json:"assertion_failures,omitempty"` // 断言失败列表 // EN: Assertion failures
+}
+
+// AssertionFailure 单个断言失败的详情
+// EN: AssertionFailure describes a single failed assertion.
+type AssertionFailure struct {
+	Field        string `json:"field"`                   // 字段路径 // EN: Field path
+	Expected     any    `json:"expected"`                // 预期值 // EN: Expected value
+	Actual       any    `json:"actual"`                  // 实际值 // EN: Actual value
+	ExpectedType string `json:"expected_type,omitempty"` // 预期值的 BSON 类型 // EN: BSON type of the expected value
+	ActualType   string `json:"actual_type,omitempty"`   // 实际值的 BSON 类型 // EN: BSON type of the actual value
 }
 
 // Summary 摘要
@@ -92,6 +106,7 @@ type Report struct {
 	ByMode      map[string]ModeStats     `json:"by_mode"`      // 按模式统计 // EN: Statistics by mode
 	Comparisons []ComparisonResult       `json:"comparisons"`  // 比较结果 // EN: Comparison results
 	Failures    []FailureDetail          `json:"failures"`     // 失败详情 // EN: Failure details
+	Skipped     []SkippedDetail          `json:"skipped"`      // 跳过详情 // EN: Skipped details
 }
 
 // ReportSummary 报告摘要
@@ -100,6 +115,8 @@ type ReportSummary struct {
 	TotalTests      int     `json:"total_tests"`      // 总测试数 // EN: Total test count
 	TotalPassed     int     `json:"total_passed"`     // 通过数 // EN: Passed count
 	TotalFailed     int     `json:"total_failed"`     // 失败数 // EN: Failed count
+	TotalSkipped    int     `json:"total_skipped"`    // 所有结果均被跳过的测试数 // EN: Tests skipped by every result file
+	TotalTimedOut   int     `json:"total_timed_out"`  // 至少一个结果超时的测试数 // EN: Tests with at least one timed-out result
 	ConsistencyRate float64 `json:"consistency_rate"` // 一致性比率 // EN: Consistency rate
 }
 
@@ -114,17 +131,19 @@ type CategoryStats struct {
 // LanguageStats 按语言统计
 // EN: LanguageStats defines statistics by language.
 type LanguageStats struct {
-	Total  int `json:"total"`  // 总数 // EN: Total count
-	Passed int `json:"passed"` // 通过数 // EN: Passed count
-	Failed int `json:"failed"` // 失败数 // EN: Failed count
+	Total   int `json:"total"`   // 总数 // EN: Total count
+	Passed  int `json:"passed"`  // 通过数 // EN: Passed count
+	Failed  int `json:"failed"`  // 失败数 // EN: Failed count
+	Skipped int `json:"skipped"` // 跳过数 // EN: Skipped count
 }
 
 // ModeStats 按模式统计
 // EN: ModeStats defines statistics by mode.
 type ModeStats struct {
-	Total  int `json:"total"`  // 总数 // EN: Total count
-	Passed int `json:"passed"` // 通过数 // EN: Passed count
-	Failed int `json:"failed"` // 失败数 // EN: Failed count
+	Total   int `json:"total"`   // 总数 // EN: Total count
+	Passed  int `json:"passed"`  // 通过数 // EN: Passed count
+	Failed  int `json:"failed"`  // 失败数 // EN: Failed count
+	Skipped int `json:"skipped"` // 跳过数 // EN: Skipped count
 }
 
 // ComparisonResult 比较结果
@@ -143,8 +162,17 @@ type ComparisonResult struct {
 // FailureDetail 失败详情
 // EN: FailureDetail defines the detail of a failed test.
 type FailureDetail struct {
+	TestName   string                        `json:"test_name"`            // 测试名称 // EN: Test name
+	Failures   map[string]string             `json:"failures"`             // 失败信息 (language_mode -> error) // EN: Failure info (language_mode -> error)
+	Assertions map[string][]AssertionFailure `json:"assertions,omitempty"` // 断言失败 (language_mode -> 断言列表) // EN: Assertion failures (language_mode -> assertion list)
+	TimedOut   []string                      `json:"timed_out,omitempty"`  // 超时的结果 (language_mode) // EN: Timed-out results (language_mode)
+}
+
+// SkippedDetail 跳过详情
+// EN: SkippedDetail defines the detail of a skipped test.
+type SkippedDetail struct {
 	TestName string            `json:"test_name"` // 测试名称 // EN: Test name
-	Failures map[string]string `json:"failures"`  // 失败信息 (language_mode -> error) // EN: Failure info (language_mode -> error)
+	Reasons  map[string]string `json:"reasons"`   // 跳过原因 (language_mode -> 原因) // EN: Skip reasons (language_mode -> reason)
 }
 
 // collectResults 收集所有结果
@@ -196,6 +224,7 @@ func generateReport(results map[string]*ResultsFile) *Report {
 		ByMode:      make(map[string]ModeStats),
 		Comparisons: []ComparisonResult{},
 		Failures:    []FailureDetail{},
+		Skipped:     []SkippedDetail{},
 	}
 
 	// 收集所有测试名称 // EN: Collect all test names
@@ -219,6 +248,8 @@ func generateReport(results map[string]*ResultsFile) *Report {
 
 	totalPassed := 0
 	totalFailed := 0
+	totalSkipped := 0
+	totalTimedOut := 0
 
 	for testName := range testNames {
 		rm := resultMap[testName]
@@ -237,16 +268,38 @@ func generateReport(results map[string]*ResultsFile) *Report {
 		successCount := 0
 		failureCount := 0
 		failures := make(map[string]string)
+		assertions := make(map[string][]AssertionFailure)
+		skipped := make(map[string]string)
+		var timedOut []string
 
 		for key, r := range rm {
+			if r.Status == "skipped" {
+				skipped[key] = r.SkipReason
+				continue
+			}
 			if r.Success {
 				successCount++
 			} else {
 				failureCount++
 				failures[key] = r.Error
+				if r.Status == "timeout" {
+					timedOut = append(timedOut, key)
+				}
+				if len(r.AssertionFailures) > 0 {
+					assertions[key] = r.AssertionFailures
+				}
 			}
 		}
 
+		if len(skipped) > 0 {
+			report.Skipped = append(report.Skipped, SkippedDetail{TestName: testName, Reasons: skipped})
+		}
+		// 所有结果都被跳过时不参与一致性统计 // EN: Tests skipped everywhere do not count towards consistency
+		if successCount+failureCount == 0 {
+			totalSkipped++
+			continue
+		}
+
 		comp.Consistent = failureCount == 0
 		report.Comparisons = append(report.Comparisons, comp)
 
@@ -254,10 +307,18 @@ func generateReport(results map[string]*ResultsFile) *Report {
 			totalPassed++
 		} else {
 			totalFailed++
-			report.Failures = append(report.Failures, FailureDetail{
+			detail := FailureDetail{
 				TestName: testName,
 				Failures: failures,
-			})
+				TimedOut: timedOut,
+			}
+			if len(timedOut) > 0 {
+				totalTimedOut++
+			}
+			if len(assertions) > 0 {
+				detail.Assertions = assertions
+			}
+			report.Failures = append(report.Failures, detail)
 		}
 	}
 
@@ -275,6 +336,7 @@ func generateReport(results map[string]*ResultsFile) *Report {
 		ls.Total += rf.Summary.Total
 		ls.Passed += rf.Summary.Passed
 		ls.Failed += rf.Summary.Failed
+		ls.Skipped += rf.Summary.Skipped
 		report.ByLanguage[lang] = ls
 
 		// 模式统计 // EN: Mode statistics
@@ -282,16 +344,19 @@ func generateReport(results map[string]*ResultsFile) *Report {
 		ms.Total += rf.Summary.Total
 		ms.Passed += rf.Summary.Passed
 		ms.Failed += rf.Summary.Failed
+		ms.Skipped += rf.Summary.Skipped
 		report.ByMode[mode] = ms
 	}
 
 	report.Summary = ReportSummary{
-		TotalTests:  len(testNames),
-		TotalPassed: totalPassed,
-		TotalFailed: totalFailed,
+		TotalTests:    len(testNames),
+		TotalPassed:   totalPassed,
+		TotalFailed:   totalFailed,
+		TotalSkipped:  totalSkipped,
+		TotalTimedOut: totalTimedOut,
 	}
-	if report.Summary.TotalTests > 0 {
-		report.Summary.ConsistencyRate = float64(totalPassed) / float64(report.Summary.TotalTests) * 100
+	if executed := totalPassed + totalFailed; executed > 0 {
+		report.Summary.ConsistencyRate = float64(totalPassed) / float64(executed) * 100
 	}
 
 	return report
@@ -331,33 +396,35 @@ func saveMarkdown(path string, report *Report) error {
 	sb.WriteString(fmt.Sprintf("| 总测试数 | %d |\n", report.Summary.TotalTests))                                  // EN: Total tests
 	sb.WriteString(fmt.Sprintf("| 通过 | %d (%.1f%%) |\n", report.Summary.TotalPassed, report.Summary.ConsistencyRate)) // EN: Passed
 	sb.WriteString(fmt.Sprintf("| 失败 | %d |\n", report.Summary.TotalFailed))                                      // EN: Failed
+	sb.WriteString(fmt.Sprintf("| 跳过 | %d |\n", report.Summary.TotalSkipped))                                     // EN: Skipped
+	sb.WriteString(fmt.Sprintf("| 超时 | %d |\n", report.Summary.TotalTimedOut))                                    // EN: Timed out
 	sb.WriteString("\n")
 
 	// 按语言统计 // EN: Statistics by language
 	sb.WriteString("## 按语言统计\n\n") // EN: Statistics by Language
-	sb.WriteString("| 语言 | 总数 | 通过 | 失败 | 通过率 |\n")
-	sb.WriteString("|------|------|------|------|--------|\n")
+	sb.WriteString("| 语言 | 总数 | 通过 | 失败 | 跳过 | 通过率 |\n")
+	sb.WriteString("|------|------|------|------|------|--------|\n")
 	for lang, stats := range report.ByLanguage {
 		rate := float64(0)
-		if stats.Total > 0 {
-			rate = float64(stats.Passed) / float64(stats.Total) * 100
+		if executed := stats.Total - stats.Skipped; executed > 0 {
+			rate = float64(stats.Passed) / float64(executed) * 100
 		}
-		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %.1f%% |\n",
-			lang, stats.Total, stats.Passed, stats.Failed, rate))
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %.1f%% |\n",
+			lang, stats.Total, stats.Passed, stats.Failed, stats.Skipped, rate))
 	}
 	sb.WriteString("\n")
 
 	// 按模式统计 // EN: Statistics by mode
 	sb.WriteString("## 按模式统计\n\n") // EN: Statistics by Mode
-	sb.WriteString("| 模式 | 总数 | 通过 | 失败 | 通过率 |\n")
-	sb.WriteString("|------|------|------|------|--------|\n")
+	sb.WriteString("| 模式 | 总数 | 通过 | 失败 | 跳过 | 通过率 |\n")
+	sb.WriteString("|------|------|------|------|------|--------|\n")
 	for mode, stats := range report.ByMode {
 		rate := float64(0)
-		if stats.Total > 0 {
-			rate = float64(stats.Passed) / float64(stats.Total) * 100
+		if executed := stats.Total - stats.Skipped; executed > 0 {
+			rate = float64(stats.Passed) / float64(executed) * 100
 		}
-		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %.1f%% |\n",
-			mode, stats.Total, stats.Passed, stats.Failed, rate))
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %.1f%% |\n",
+			mode, stats.Total, stats.Passed, stats.Failed, stats.Skipped, rate))
 	}
 	sb.WriteString("\n")
 
@@ -367,18 +434,76 @@ func saveMarkdown(path string, report *Report) error {
 		for _, f := range report.Failures {
 			sb.WriteString(fmt.Sprintf("### %s\n\n", f.TestName))
 			for key, err := range f.Failures {
+				if containsString(f.TimedOut, key) {
+					sb.WriteString(fmt.Sprintf("- **%s** (超时): %s\n", key, err)) // EN: (timed out)
+					continue
+				}
+				if assertions := f.Assertions[key]; len(assertions) > 0 {
+					writeAssertionTable(&sb, key, assertions)
+					continue
+				}
 				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", key, err))
 			}
 			sb.WriteString("\n")
 		}
 	}
 
+	// 跳过详情 // EN: Skipped details
+	if len(report.Skipped) > 0 {
+		sb.WriteString("## 跳过的测试\n\n") // EN: Skipped Tests
+		sb.WriteString("| 测试 | 结果 | 原因 |\n") // EN: Test | Result | Reason
+		sb.WriteString("|------|------|------|\n")
+		for _, d := range report.Skipped {
+			for key, reason := range d.Reasons {
+				sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", d.TestName, key, strings.ReplaceAll(reason, "|", "\\|")))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("---\n\n")
 	sb.WriteString("*报告由 MonoLite 一致性验证器自动生成*\n") // EN: Report automatically generated by MonoLite consistency verifier
 
 	return os.WriteFile(path, []byte(sb.String()), 0644)
 }
 
+// containsString 判断切片是否包含字符串
+// EN: containsString reports whether the slice contains the string.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAssertionTable 以表格形式输出断言失败
+// EN: writeAssertionTable writes assertion failures as a table.
+func writeAssertionTable(sb *strings.Builder, key string, assertions []AssertionFailure) {
+	sb.WriteString(fmt.Sprintf("- **%s**:\n\n", key))
+	sb.WriteString("| 字段 | 预期 | 预期类型 | 实际 | 实际类型 |\n") // EN: Field | Expected | Expected type | Actual | Actual type
+	sb.WriteString("|------|------|----------|------|----------|\n")
+	for _, a := range assertions {
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s |\n",
+			a.Field, formatCell(a.Expected), a.ExpectedType, formatCell(a.Actual), a.ActualType))
+	}
+	sb.WriteString("\n")
+}
+
+// formatCell 将值格式化为 Markdown 表格单元格
+// EN: formatCell formats a value as a Markdown table cell.
+func formatCell(v any) string {
+	if v == nil {
+		return "`null`"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("`%v`", v)
+	}
+	return "`" + strings.ReplaceAll(string(data), "|", "\\|") + "`"
+}
+
 // printSummary 打印摘要
 // EN: printSummary prints the summary to console.
 func printSummary(report *Report) {
@@ -386,4 +511,6 @@ func printSummary(report *Report) {
 	log.Printf("总测试数: %d", report.Summary.TotalTests)                                            // EN: Total tests
 	log.Printf("通过: %d (%.1f%%)", report.Summary.TotalPassed, report.Summary.ConsistencyRate) // EN: Passed
 	log.Printf("失败: %d", report.Summary.TotalFailed)                                            // EN: Failed
+	log.Printf("跳过: %d", report.Summary.TotalSkipped)                                           // EN: Skipped
+	log.Printf("超时: %d", report.Summary.TotalTimedOut)                                          // EN: Timed out
 }